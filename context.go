@@ -0,0 +1,42 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/internal/exec"
+	"github.com/graph-gophers/graphql-go/log"
+)
+
+// FieldInfoFromContext returns the field being resolved with ctx, including its path in the
+// response, e.g. for logging, caching by path or building errors. It is available to resolvers
+// accepting a context, field middleware and directive handlers.
+func FieldInfoFromContext(ctx context.Context) (FieldInfo, bool) {
+	f, path, ok := exec.FieldFromContext(ctx)
+	if !ok {
+		return FieldInfo{}, false
+	}
+	return newFieldInfo(f, path), true
+}
+
+type operationKey struct{}
+
+// OperationFromContext returns the operation being executed with ctx. Its name is empty for
+// anonymous operations. It must not be modified.
+func OperationFromContext(ctx context.Context) (*ast.OperationDefinition, bool) {
+	op, ok := ctx.Value(operationKey{}).(*ast.OperationDefinition)
+	return op, ok
+}
+
+// withOperation returns a context holding the operation being executed.
+func withOperation(ctx context.Context, op *ast.OperationDefinition) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+type loggerKey struct{}
+
+// withLogger returns a context holding the logger of the schema, which logs the errors masked by
+// [MaskErrors].
+func withLogger(ctx context.Context, logger log.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
@@ -0,0 +1,104 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const contextInfoSchema = `
+	type Query {
+		posts: [Post!]!
+	}
+	type Post {
+		title: String!
+		author: String!
+	}
+`
+
+type contextInfoResolver struct {
+	mu    sync.Mutex
+	infos []string
+}
+
+func (r *contextInfoResolver) record(ctx context.Context) {
+	info, ok := graphql.FieldInfoFromContext(ctx)
+	if !ok {
+		panic("no field info")
+	}
+	opName := "-"
+	if op, ok := graphql.OperationFromContext(ctx); ok {
+		opName = op.Name.Name
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.infos = append(r.infos, fmt.Sprintf("%s %s.%s %s %v %s", opName, info.TypeName, info.FieldName, info.Alias, info.Path, info.Definition.Type))
+}
+
+func (r *contextInfoResolver) Posts(ctx context.Context) []*contextInfoPost {
+	r.record(ctx)
+	return []*contextInfoPost{{r: r, title: "a"}, {r: r, title: "b"}}
+}
+
+type contextInfoPost struct {
+	r     *contextInfoResolver
+	title string
+}
+
+func (p *contextInfoPost) Title(ctx context.Context) string {
+	p.r.record(ctx)
+	return p.title
+}
+
+var contextInfoLoader = &graphql.Loader[string, string]{
+	Name: "authors",
+	Fetch: func(ctx context.Context, keys []string) ([]string, []error) {
+		return keys, nil
+	},
+}
+
+// Author returns a thunk, so that its resolver is called before the sibling fields are executed.
+func (p *contextInfoPost) Author(ctx context.Context) func() (string, error) {
+	p.r.record(ctx)
+	return contextInfoLoader.Load(ctx, "author of "+p.title)
+}
+
+func TestFieldInfoFromContext(t *testing.T) {
+	r := &contextInfoResolver{}
+	s := graphql.MustParseSchema(contextInfoSchema, r)
+	res := s.Exec(context.Background(), `query Feed { list: posts { title by: author } }`, "", nil)
+	if len(res.Errors) != 0 {
+		t.Fatal(res.Errors)
+	}
+	want := []string{
+		"Feed Post.author by [list 0 by] String!",
+		"Feed Post.author by [list 1 by] String!",
+		"Feed Post.title title [list 0 title] String!",
+		"Feed Post.title title [list 1 title] String!",
+		"Feed Query.posts list [list] [Post!]!",
+	}
+	slices.Sort(r.infos)
+	if !slices.Equal(r.infos, want) {
+		t.Errorf("got field infos\n%q\nwant\n%q", r.infos, want)
+	}
+
+	// Anonymous operations have an empty name.
+	r.infos = nil
+	s.Exec(context.Background(), `{ posts { title } }`, "", nil)
+	if len(r.infos) == 0 || r.infos[0] != " Query.posts posts [posts] [Post!]!" {
+		t.Errorf("got field infos %q", r.infos)
+	}
+}
+
+func TestFieldInfoFromContextWithoutField(t *testing.T) {
+	if _, ok := graphql.FieldInfoFromContext(context.Background()); ok {
+		t.Error("got field info without a field")
+	}
+	if _, ok := graphql.OperationFromContext(context.Background()); ok {
+		t.Error("got operation without an operation")
+	}
+}
@@ -0,0 +1,62 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/validation"
+)
+
+// MaxCost specifies the maximum static cost of an operation. The cost is computed before the
+// execution from the @cost and @listSize directives of the schema, following the GraphQL
+// Foundation cost specification, and reported in the "cost" extension of the response. Operations
+// exceeding the maximum cost are rejected. The default is 0 which disables cost analysis.
+//
+// The directives must be declared in the schema:
+//
+//	directive @cost(weight: String!) on ARGUMENT_DEFINITION | ENUM_VALUE | FIELD_DEFINITION | INPUT_FIELD_DEFINITION | OBJECT | SCALAR
+//	directive @listSize(assumedSize: Int, slicingArguments: [String!], sizedFields: [String!], requireOneSlicingArgument: Boolean = true) on FIELD_DEFINITION
+//
+// Fields default to a weight of 1 for composite types and 0 for scalars and enums, and lists
+// without a @listSize directive are assumed to have 10 items. Costs are capped at math.MaxInt32.
+func MaxCost(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.maxCost = n
+	}
+}
+
+type costKey struct{}
+
+// OperationCost returns the static cost of the operation being executed. It is available to
+// resolvers, field middleware and directive handlers if MaxCost is set.
+func OperationCost(ctx context.Context) (int, bool) {
+	cost, ok := ctx.Value(costKey{}).(int)
+	return cost, ok
+}
+
+// checkCost computes the cost of an operation if cost analysis is enabled. It returns the context
+// holding the cost, the extensions reporting it and the error if the cost exceeds the maximum.
+func (s *Schema) checkCost(ctx context.Context, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]any) (context.Context, map[string]any, []*errors.QueryError) {
+	if s.maxCost <= 0 {
+		return ctx, nil, nil
+	}
+	finish := s.validationTracer.TraceValidation(ctx)
+	// The cost is capped at validation.MaxCostValue, so it fits into an int.
+	c := validation.Cost(s.schema, doc, op, variables)
+	exceeded := c > float64(s.maxCost)
+	cost := int(c)
+	extensions := map[string]any{"cost": map[string]any{"requestedQueryCost": cost, "maximumAvailable": s.maxCost}}
+	var errs []*errors.QueryError
+	if exceeded {
+		errs = []*errors.QueryError{{
+			Message:    fmt.Sprintf("Operation has cost %d that exceeds max cost %d", cost, s.maxCost),
+			Locations:  []errors.Location{op.Loc},
+			Rule:       "MaxCostExceeded",
+			Extensions: map[string]any{"code": "MAX_COST_EXCEEDED"},
+		}}
+	}
+	finish(errs)
+	return context.WithValue(ctx, costKey{}, cost), extensions, errs
+}
@@ -0,0 +1,85 @@
+package graphql_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const costSchema = `
+	directive @cost(weight: String!) on ARGUMENT_DEFINITION | ENUM_VALUE | FIELD_DEFINITION | INPUT_FIELD_DEFINITION | OBJECT | SCALAR
+	directive @listSize(assumedSize: Int, slicingArguments: [String!], sizedFields: [String!], requireOneSlicingArgument: Boolean = true) on FIELD_DEFINITION
+
+	type Query {
+		items(first: Int): [Item!]! @listSize(slicingArguments: ["first"])
+		cost: Int!
+	}
+
+	type Item {
+		name: String!
+		price: Int! @cost(weight: "2")
+		items(first: Int): [Item!]! @listSize(slicingArguments: ["first"])
+	}
+`
+
+type costResolver struct{}
+
+func (costResolver) Items(args struct{ First *int32 }) []costItem {
+	return []costItem{{}}
+}
+
+func (costResolver) Cost(ctx context.Context) int32 {
+	cost, _ := graphql.OperationCost(ctx)
+	return int32(cost)
+}
+
+type costItem struct{}
+
+func (costItem) Name() string { return "item" }
+func (costItem) Price() int32 { return 1 }
+func (costItem) Items(args struct{ First *int32 }) []costItem {
+	return nil
+}
+
+func TestMaxCost(t *testing.T) {
+	schema := graphql.MustParseSchema(costSchema, &costResolver{}, graphql.MaxCost(20))
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		vars  map[string]any
+		want  string
+	}{
+		{
+			name:  "within limit",
+			query: `{ items(first: 5) { price } cost }`,
+			want:  `{"data":{"items":[{"price":1}],"cost":15},"extensions":{"cost":{"maximumAvailable":20,"requestedQueryCost":15}}}`,
+		},
+		{
+			name:  "capped",
+			query: "{ " + strings.Repeat("items(first: 2147483647) { ", 40) + "name" + strings.Repeat(" }", 40) + " }",
+			want:  `{"errors":[{"message":"Operation has cost 2147483647 that exceeds max cost 20","locations":[{"line":1,"column":1}],"extensions":{"code":"MAX_COST_EXCEEDED"}}],"extensions":{"cost":{"maximumAvailable":20,"requestedQueryCost":2147483647}}}`,
+		},
+		{
+			name:  "exceeds limit",
+			query: `query($n: Int) { items(first: $n) { price } }`,
+			vars:  map[string]any{"n": 10},
+			want:  `{"errors":[{"message":"Operation has cost 30 that exceeds max cost 20","locations":[{"line":1,"column":1}],"extensions":{"code":"MAX_COST_EXCEEDED"}}],"extensions":{"cost":{"maximumAvailable":20,"requestedQueryCost":30}}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := responseJSON(t, schema.Exec(context.Background(), tc.query, "", tc.vars)); got != tc.want {
+				t.Errorf("wrong response\nwant: %s\ngot:  %s", tc.want, got)
+			}
+		})
+	}
+
+	// Without MaxCost the cost is not computed.
+	schema = graphql.MustParseSchema(costSchema, &costResolver{})
+	want := `{"data":{"cost":0}}`
+	if got := responseJSON(t, schema.Exec(context.Background(), `{ cost }`, "", nil)); got != want {
+		t.Errorf("wrong response\nwant: %s\ngot:  %s", want, got)
+	}
+}
@@ -0,0 +1,139 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/graph-gophers/graphql-go/internal/exec"
+	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+	"github.com/graph-gophers/graphql-go/internal/exec/selected"
+)
+
+// Directive describes a directive applied in the schema to a field, to an argument of a field or
+// to the object type of a field, or a directive applied in a query.
+type Directive struct {
+	// Name is the name of the directive without the @.
+	Name string
+	// Location is the location the directive is applied to, which is OBJECT, FIELD_DEFINITION or
+	// ARGUMENT_DEFINITION in the schema, and FIELD, FRAGMENT_SPREAD, INLINE_FRAGMENT, QUERY,
+	// MUTATION or SUBSCRIPTION in a query.
+	Location string
+	// Argument is the name of the argument the directive is applied to. The value of the argument
+	// is in the Args of the FieldInfo.
+	Argument string
+	// Args holds the evaluated arguments of the directive. It must not be modified.
+	Args map[string]any
+}
+
+// DirectiveHandler wraps the resolution of the fields affected by a directive like a
+// [FieldMiddleware]. A directive applied to an object type affects all fields of the type, and a
+// directive applied to an argument affects the field of the argument. A directive applied in a
+// query to a fragment affects the fields of the fragment, and a directive applied to an operation
+// affects its root fields.
+type DirectiveHandler func(ctx context.Context, d Directive, info FieldInfo, next Resolve) (any, error)
+
+// DirectiveHandlers registers handlers for the directives applied in the schema by directive
+// name, for example:
+//
+//	graphql.DirectiveHandlers(map[string]graphql.DirectiveHandler{
+//		"auth": func(ctx context.Context, d graphql.Directive, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+//			if !hasRole(ctx, d.Args["requires"].(string)) {
+//				return nil, errors.New("forbidden")
+//			}
+//			return next(ctx)
+//		},
+//	})
+//
+// The handlers of a field are invoked inside of the field middleware. The handlers of directives
+// applied in the schema are invoked first: the handlers of directives applied to the object type,
+// followed by the handlers of directives applied to the field and to its arguments. The handlers
+// of directives applied in the query are invoked next: the handlers of directives applied to the
+// operation, followed by the handlers of directives applied to fragments and to the field.
+//
+// The resolver of a field is only called by the innermost next, so that a handler returning an
+// error without calling next, e.g. an authorization check, prevents the resolver from running. As
+// with field middleware, resolvers returning thunks (see [Loader]) of fields with handlers are not
+// called ahead of their siblings, and the keys they load are not batched with the keys of the
+// other items of a list.
+//
+// Directives applied in a query must be declared in the schema with executable locations, for
+// example:
+//
+//	directive @format(unit: Unit = METER) on FIELD
+//
+// The directives of the specification are handled by the library, as are @timeout if it is
+// defined as in [FieldTimeouts], and @cost and @listSize if [MaxCost] is set. Parsing the schema
+// fails if a handler is registered for one of them.
+func DirectiveHandlers(handlers map[string]DirectiveHandler) SchemaOpt {
+	return func(s *Schema) {
+		m := maps.Clone(s.directiveHandlers)
+		if m == nil {
+			m = make(map[string]DirectiveHandler, len(handlers))
+		}
+		maps.Copy(m, handlers)
+		s.directiveHandlers = m
+	}
+}
+
+// StrictDirectives fails to parse the schema if a directive applied to an object type, a field or
+// an argument has no handler. The directives of the specification, like @deprecated, do not need
+// a handler.
+func StrictDirectives() SchemaOpt {
+	return func(s *Schema) {
+		s.strictDirectives = true
+	}
+}
+
+// DirectiveArgs returns the evaluated arguments of a directive applied in the query to the field
+// being resolved, including directives applied to the enclosing fragments and to the operation. It
+// is available to resolvers, field middleware and directive handlers, also for directives without
+// a handler. If the directive is applied more than once, the arguments of the innermost directive
+// are returned. The returned map must not be modified.
+func DirectiveArgs(ctx context.Context, name string) (map[string]any, bool) {
+	ds := exec.DirectivesFromContext(ctx)
+	for i := len(ds) - 1; i >= 0; i-- {
+		if ds[i].Name == name {
+			return ds[i].Args, true
+		}
+	}
+	return nil, false
+}
+
+// resolvableDirectives returns the directive handlers of the schema for binding them to fields.
+func (s *Schema) resolvableDirectives() *resolvable.DirectiveHandlers {
+	if len(s.directiveHandlers) == 0 && !s.strictDirectives {
+		return nil
+	}
+	handlers := make(map[string]any, len(s.directiveHandlers))
+	for name, h := range s.directiveHandlers {
+		handlers[name] = h
+	}
+	return &resolvable.DirectiveHandlers{Handlers: handlers, Strict: s.strictDirectives, Cost: s.maxCost > 0}
+}
+
+// validateDirectiveHandlers checks that no handler is registered for a directive handled by the
+// library.
+func (s *Schema) validateDirectiveHandlers() error {
+	for _, name := range slices.Sorted(maps.Keys(s.directiveHandlers)) {
+		if resolvable.IsBuiltinDirective(name) || (name == "timeout" && resolvable.HasTimeoutDirective(s.schema)) {
+			return fmt.Errorf("directive handler for @%s conflicts with the built-in directive", name)
+		}
+		if s.maxCost > 0 && resolvable.IsCostDirective(name) {
+			return fmt.Errorf("directive handler for @%s conflicts with cost analysis, which is enabled by MaxCost", name)
+		}
+	}
+	return nil
+}
+
+// execDirectiveHandler returns the function calling the directive handlers bound to fields.
+func (s *Schema) execDirectiveHandler() exec.DirectiveHandler {
+	if len(s.directiveHandlers) == 0 {
+		return nil
+	}
+	return func(ctx context.Context, d *resolvable.Directive, f *selected.SchemaField, path []any, next func(context.Context) (any, error)) (any, error) {
+		h := d.Handler.(DirectiveHandler)
+		return h(ctx, Directive{Name: d.Name, Location: d.Location, Argument: d.Argument, Args: d.Args}, newFieldInfo(f, path), next)
+	}
+}
@@ -0,0 +1,473 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const directivesSchema = `
+	directive @auth(requires: Role = ADMIN) on OBJECT | FIELD_DEFINITION
+	directive @uppercase on FIELD_DEFINITION
+	directive @trim on ARGUMENT_DEFINITION
+	directive @log(level: String) on FIELD_DEFINITION
+
+	enum Role {
+		ADMIN
+		USER
+	}
+
+	type Query {
+		greet(name: String! @trim): String! @uppercase
+		me: User! @auth(requires: USER)
+		secret: Secret
+	}
+
+	type User {
+		name: String! @uppercase @log
+		old: String @deprecated
+	}
+
+	type Secret @auth {
+		value: String!
+	}
+`
+
+type directivesResolver struct{}
+
+func (directivesResolver) Greet(args struct{ Name string }) string { return "hello " + args.Name }
+func (directivesResolver) Me() *directivesUser                     { return &directivesUser{} }
+func (directivesResolver) Secret() *directivesSecret               { return &directivesSecret{} }
+
+type directivesUser struct{}
+
+func (directivesUser) Name() string { return "alice" }
+func (directivesUser) Old() *string { return nil }
+
+type directivesSecret struct{}
+
+func (directivesSecret) Value() string { return "42" }
+
+type roleKey struct{}
+
+func directiveHandlers(calls *[]string) map[string]graphql.DirectiveHandler {
+	return map[string]graphql.DirectiveHandler{
+		"auth": func(ctx context.Context, d graphql.Directive, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+			if role, _ := ctx.Value(roleKey{}).(string); role != d.Args["requires"] && role != "ADMIN" {
+				return nil, fmt.Errorf("%s.%s requires role %s", info.TypeName, info.FieldName, d.Args["requires"])
+			}
+			return next(ctx)
+		},
+		"uppercase": func(ctx context.Context, d graphql.Directive, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+			*calls = append(*calls, "uppercase")
+			v, err := next(ctx)
+			if s, ok := v.(string); ok {
+				return strings.ToUpper(s), err
+			}
+			return v, err
+		},
+		"trim": func(ctx context.Context, d graphql.Directive, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+			if s, ok := info.Args[d.Argument].(string); ok && strings.TrimSpace(s) == "" {
+				return nil, fmt.Errorf("argument %q of %s must not be blank (%s)", d.Argument, info.FieldName, d.Location)
+			}
+			return next(ctx)
+		},
+		"log": func(ctx context.Context, d graphql.Directive, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+			*calls = append(*calls, "log")
+			return next(ctx)
+		},
+	}
+}
+
+func TestDirectiveHandlers(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		role  string
+		query string
+		want  string
+		calls []string
+	}{
+		{
+			name:  "field definition",
+			query: `{ greet(name: "bob") }`,
+			want:  `{"data":{"greet":"HELLO BOB"}}`,
+			calls: []string{"uppercase"},
+		},
+		{
+			name:  "argument definition",
+			query: `{ greet(name: " ") }`,
+			want:  `{"errors":[{"message":"argument \"name\" of greet must not be blank (ARGUMENT_DEFINITION)","locations":[{"line":1,"column":3}],"path":["greet"]}],"data":null}`,
+			calls: []string{"uppercase"},
+		},
+		{
+			name:  "arguments",
+			query: `{ me { name } }`,
+			want:  `{"errors":[{"message":"Query.me requires role USER","locations":[{"line":1,"column":3}],"path":["me"]}],"data":null}`,
+		},
+		{
+			name:  "order",
+			role:  "USER",
+			query: `{ me { name } }`,
+			want:  `{"data":{"me":{"name":"ALICE"}}}`,
+			calls: []string{"uppercase", "log"},
+		},
+		{
+			name:  "object with default arguments",
+			role:  "USER",
+			query: `{ secret { value } }`,
+			want:  `{"errors":[{"message":"Secret.value requires role ADMIN","locations":[{"line":1,"column":12}],"path":["secret","value"]}],"data":{"secret":null}}`,
+		},
+		{
+			name:  "object",
+			role:  "ADMIN",
+			query: `{ secret { value } }`,
+			want:  `{"data":{"secret":{"value":"42"}}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			schema := graphql.MustParseSchema(directivesSchema, &directivesResolver{}, graphql.DirectiveHandlers(directiveHandlers(&calls)))
+			ctx := context.WithValue(context.Background(), roleKey{}, tc.role)
+			if got := responseJSON(t, schema.Exec(ctx, tc.query, "", nil)); got != tc.want {
+				t.Errorf("wrong response\nwant: %s\ngot:  %s", tc.want, got)
+			}
+			if strings.Join(calls, ",") != strings.Join(tc.calls, ",") {
+				t.Errorf("wrong calls\nwant: %v\ngot:  %v", tc.calls, calls)
+			}
+		})
+	}
+}
+
+func TestDirectiveHandlersMiddlewareOrder(t *testing.T) {
+	var calls []string
+	schema := graphql.MustParseSchema(directivesSchema, &directivesResolver{},
+		graphql.DirectiveHandlers(directiveHandlers(&calls)),
+		graphql.UseFieldMiddleware(func(ctx context.Context, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+			v, err := next(ctx)
+			if info.FieldName == "greet" {
+				calls = append(calls, fmt.Sprintf("middleware %v", v))
+			}
+			return v, err
+		}),
+	)
+	want := `{"data":{"greet":"HELLO BOB"}}`
+	if got := responseJSON(t, schema.Exec(context.Background(), `{ greet(name: "bob") }`, "", nil)); got != want {
+		t.Errorf("wrong response\nwant: %s\ngot:  %s", want, got)
+	}
+	if got, want := strings.Join(calls, ","), "uppercase,middleware HELLO BOB"; got != want {
+		t.Errorf("wrong calls\nwant: %v\ngot:  %v", want, got)
+	}
+}
+
+type directivesThunkResolver struct {
+	calls atomic.Int32
+}
+
+func (r *directivesThunkResolver) Secrets() []*directivesThunkSecret {
+	return []*directivesThunkSecret{{r: r}, {r: r}}
+}
+
+type directivesThunkSecret struct {
+	r *directivesThunkResolver
+}
+
+func (s *directivesThunkSecret) Value() func() (string, error) {
+	s.r.calls.Add(1)
+	return func() (string, error) { return "42", nil }
+}
+
+func TestDirectiveHandlersThunk(t *testing.T) {
+	const schemaString = `
+		directive @auth(requires: Role = ADMIN) on OBJECT | FIELD_DEFINITION
+		enum Role {
+			ADMIN
+			USER
+		}
+		type Query {
+			secrets: [Secret]!
+		}
+		type Secret {
+			value: String! @auth
+		}
+	`
+	for _, tc := range []struct {
+		name  string
+		role  string
+		want  string
+		calls int32
+	}{
+		{
+			name: "denied",
+			role: "USER",
+			want: `{"errors":[` +
+				`{"message":"Secret.value requires role ADMIN","locations":[{"line":1,"column":13}],"path":["secrets",0,"value"]},` +
+				`{"message":"Secret.value requires role ADMIN","locations":[{"line":1,"column":13}],"path":["secrets",1,"value"]}` +
+				`],"data":{"secrets":[null,null]}}`,
+		},
+		{
+			name:  "allowed",
+			role:  "ADMIN",
+			want:  `{"data":{"secrets":[{"value":"42"},{"value":"42"}]}}`,
+			calls: 2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			r := &directivesThunkResolver{}
+			schema := graphql.MustParseSchema(schemaString, r, graphql.DirectiveHandlers(directiveHandlers(&calls)))
+			ctx := context.WithValue(context.Background(), roleKey{}, tc.role)
+			res := schema.Exec(ctx, `{ secrets { value } }`, "", nil)
+			sort.Slice(res.Errors, func(i, j int) bool {
+				return res.Errors[i].Path[1].(int) < res.Errors[j].Path[1].(int)
+			})
+			if got := responseJSON(t, res); got != tc.want {
+				t.Errorf("wrong response\nwant: %s\ngot:  %s", tc.want, got)
+			}
+			if got := r.calls.Load(); got != tc.calls {
+				t.Errorf("resolver was called %d times, want %d", got, tc.calls)
+			}
+		})
+	}
+}
+
+func TestStrictDirectives(t *testing.T) {
+	handlers := directiveHandlers(new([]string))
+	delete(handlers, "trim")
+
+	_, err := graphql.ParseSchema(directivesSchema, &directivesResolver{}, graphql.DirectiveHandlers(handlers), graphql.StrictDirectives())
+	if want := "no handler for directive @trim applied to Query.greet(name:)"; err == nil || err.Error() != want {
+		t.Errorf("wrong error\nwant: %s\ngot:  %v", want, err)
+	}
+
+	// Without strict mode, directives without a handler are ignored.
+	schema, err := graphql.ParseSchema(directivesSchema, &directivesResolver{}, graphql.DirectiveHandlers(handlers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"data":{"greet":"HELLO  "}}`
+	if got := responseJSON(t, schema.Exec(context.Background(), `{ greet(name: " ") }`, "", nil)); got != want {
+		t.Errorf("wrong response\nwant: %s\ngot:  %s", want, got)
+	}
+
+	// The directives of the specification do not need a handler.
+	if _, err := graphql.ParseSchema(directivesSchema, &directivesResolver{}, graphql.DirectiveHandlers(directiveHandlers(new([]string))), graphql.StrictDirectives()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := graphql.ParseSchema(directivesSchema, &directivesResolver{}, graphql.StrictDirectives()); err == nil {
+		t.Errorf("expected error without handlers")
+	}
+}
+
+func TestDirectiveHandlersReservedNames(t *testing.T) {
+	const schemaString = `
+		directive @cost(weight: String!) on FIELD_DEFINITION
+		directive @timeout(ms: Int!) on FIELD_DEFINITION
+
+		type Query {
+			hello: String! @cost(weight: "2")
+		}
+	`
+	var calls []string
+	handler := func(ctx context.Context, d graphql.Directive, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+		calls = append(calls, d.Name+"("+d.Args["weight"].(string)+")")
+		return next(ctx)
+	}
+	costHandler := graphql.DirectiveHandlers(map[string]graphql.DirectiveHandler{"cost": handler})
+
+	// Without cost analysis, @cost is an ordinary directive.
+	schema, err := graphql.ParseSchema(schemaString, &helloResolver{}, costHandler, graphql.StrictDirectives())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := responseJSON(t, schema.Exec(context.Background(), `{ hello }`, "", nil)), `{"data":{"hello":"Hello world!"}}`; got != want {
+		t.Errorf("wrong response\nwant: %s\ngot:  %s", want, got)
+	}
+	if len(calls) != 1 || calls[0] != "cost(2)" {
+		t.Errorf("got calls %q", calls)
+	}
+
+	// A @timeout directive with other arguments than the one of the library is an ordinary
+	// directive.
+	calls = nil
+	custom := `
+		directive @timeout(seconds: Int!, weight: String! = "0") on FIELD_DEFINITION
+
+		type Query {
+			hello: String! @timeout(seconds: 1)
+		}
+	`
+	schema, err = graphql.ParseSchema(custom, &helloResolver{}, graphql.DirectiveHandlers(map[string]graphql.DirectiveHandler{"timeout": handler}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := responseJSON(t, schema.Exec(context.Background(), `{ hello }`, "", nil)), `{"data":{"hello":"Hello world!"}}`; got != want {
+		t.Errorf("wrong response\nwant: %s\ngot:  %s", want, got)
+	}
+	if len(calls) != 1 || calls[0] != "timeout(0)" {
+		t.Errorf("got calls %q", calls)
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts []graphql.SchemaOpt
+		want string
+	}{
+		{
+			name: "cost analysis",
+			opts: []graphql.SchemaOpt{costHandler, graphql.MaxCost(10)},
+			want: "directive handler for @cost conflicts with cost analysis, which is enabled by MaxCost",
+		},
+		{
+			name: "timeout",
+			opts: []graphql.SchemaOpt{graphql.DirectiveHandlers(map[string]graphql.DirectiveHandler{"timeout": handler})},
+			want: "directive handler for @timeout conflicts with the built-in directive",
+		},
+		{
+			name: "specification",
+			opts: []graphql.SchemaOpt{graphql.DirectiveHandlers(map[string]graphql.DirectiveHandler{"skip": handler})},
+			want: "directive handler for @skip conflicts with the built-in directive",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := graphql.ParseSchema(schemaString, &helloResolver{}, tc.opts...)
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("wrong error\nwant: %s\ngot:  %v", tc.want, err)
+			}
+		})
+	}
+}
+
+const queryDirectivesSchema = `
+	directive @format(unit: Unit = METER) on FIELD
+	directive @locale(lang: String!) on QUERY | FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+
+	enum Unit {
+		METER
+		FOOT
+	}
+
+	type Query {
+		height: Float!
+		greeting: String!
+		item: Item!
+	}
+
+	type Item {
+		name: String!
+	}
+`
+
+type queryDirectivesResolver struct{}
+
+func (queryDirectivesResolver) Height() float64 { return 2 }
+
+func (queryDirectivesResolver) Greeting(ctx context.Context) string {
+	if args, ok := graphql.DirectiveArgs(ctx, "locale"); ok && args["lang"] == "de" {
+		return "hallo"
+	}
+	return "hello"
+}
+
+func (queryDirectivesResolver) Item() queryDirectivesItem { return queryDirectivesItem{} }
+
+type queryDirectivesItem struct{}
+
+func (queryDirectivesItem) Name(ctx context.Context) string {
+	if args, ok := graphql.DirectiveArgs(ctx, "locale"); ok {
+		return "item (" + args["lang"].(string) + ")"
+	}
+	return "item"
+}
+
+func TestQueryDirectives(t *testing.T) {
+	var mu sync.Mutex
+	var locations []string
+	schema := graphql.MustParseSchema(queryDirectivesSchema, &queryDirectivesResolver{}, graphql.DirectiveHandlers(map[string]graphql.DirectiveHandler{
+		"format": func(ctx context.Context, d graphql.Directive, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+			v, err := next(ctx)
+			if f, ok := v.(float64); ok && d.Args["unit"] == "FOOT" {
+				return f * 3.28084, err
+			}
+			return v, err
+		},
+		"locale": func(ctx context.Context, d graphql.Directive, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+			mu.Lock()
+			locations = append(locations, info.Alias+" "+d.Location)
+			mu.Unlock()
+			return next(ctx)
+		},
+	}))
+
+	for _, tc := range []struct {
+		name      string
+		query     string
+		vars      map[string]any
+		want      string
+		locations []string
+	}{
+		{
+			name:  "field",
+			query: `{ a: height @format(unit: FOOT) b: height @format c: height }`,
+			want:  `{"data":{"a":6.56168,"b":2,"c":2}}`,
+		},
+		{
+			name:      "variables",
+			query:     `query($lang: String!) { greeting @locale(lang: $lang) }`,
+			vars:      map[string]any{"lang": "de"},
+			want:      `{"data":{"greeting":"hallo"}}`,
+			locations: []string{"greeting FIELD"},
+		},
+		{
+			name:      "operation",
+			query:     `query @locale(lang: "de") { greeting item { name } }`,
+			want:      `{"data":{"greeting":"hallo","item":{"name":"item"}}}`,
+			locations: []string{"greeting QUERY", "item QUERY"},
+		},
+		{
+			name:      "fragments",
+			query:     `{ item { ...f @locale(lang: "fr") ... @locale(lang: "it") { n: name @locale(lang: "es") } } } fragment f on Item { name }`,
+			want:      `{"data":{"item":{"name":"item (fr)","n":"item (es)"}}}`,
+			locations: []string{"n FIELD", "n INLINE_FRAGMENT", "name FRAGMENT_SPREAD"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			locations = nil
+			if got := responseJSON(t, schema.Exec(context.Background(), tc.query, "", tc.vars)); got != tc.want {
+				t.Errorf("wrong response\nwant: %s\ngot:  %s", tc.want, got)
+			}
+			// Sibling fields are resolved concurrently.
+			sort.Strings(locations)
+			if strings.Join(locations, ",") != strings.Join(tc.locations, ",") {
+				t.Errorf("wrong locations\nwant: %v\ngot:  %v", tc.locations, locations)
+			}
+		})
+	}
+}
+
+func TestQueryDirectivesValidation(t *testing.T) {
+	schema := graphql.MustParseSchema(queryDirectivesSchema, &queryDirectivesResolver{})
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{
+			query: `{ item { ... on Item @format { name } } }`,
+			want:  `Directive "@format" may not be used on INLINE_FRAGMENT.`,
+		},
+		{
+			query: `{ height @format(unit: INCH) }`,
+			want:  `Value "INCH" does not exist in "Unit" enum.`,
+		},
+	} {
+		res := schema.Exec(context.Background(), tc.query, "", nil)
+		if len(res.Errors) == 0 || !strings.HasPrefix(res.Errors[0].Message, tc.want) {
+			t.Errorf("wrong errors for %s\nwant: %s\ngot:  %v", tc.query, tc.want, res.Errors)
+		}
+	}
+}
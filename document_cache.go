@@ -0,0 +1,152 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/lru"
+	"github.com/graph-gophers/graphql-go/internal/validation"
+)
+
+// DocumentCache enables a cache of up to size parsed and validated documents, keyed by the query
+// string and evicting the least recently used documents first. A cached document skips parsing
+// and the validation rules that do not depend on variables. The variable values are validated
+// for every request. The cache is used by [Schema.Exec], [Schema.ExecRequest],
+// [Schema.Subscribe] and [Schema.SubscribeRequest], see [Schema.DocumentCacheStats] for its
+// hit and miss counters. A size of 0 or less disables the cache.
+func DocumentCache(size int) SchemaOpt {
+	return func(s *Schema) {
+		if size <= 0 {
+			s.documentCache = nil
+			return
+		}
+		s.documentCache = newDocumentCache(size)
+	}
+}
+
+// DocumentCacheStats holds the counters of a document cache.
+type DocumentCacheStats struct {
+	// Hits is the number of requests whose document was found in the cache.
+	Hits uint64
+	// Misses is the number of requests whose document had to be parsed and validated.
+	Misses uint64
+	// Size is the number of documents in the cache.
+	Size int
+}
+
+// DocumentCacheStats returns the counters of the cache enabled with [DocumentCache]. All counters
+// are zero if the cache is not enabled.
+func (s *Schema) DocumentCacheStats() DocumentCacheStats {
+	c := s.documentCache
+	if c == nil {
+		return DocumentCacheStats{}
+	}
+	return DocumentCacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Size:   c.cache.Len(),
+	}
+}
+
+type documentCache struct {
+	size   int
+	cache  *lru.Cache[string, *validatedDocument]
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func newDocumentCache(size int) *documentCache {
+	return &documentCache{size: size, cache: lru.New[string, *validatedDocument](size)}
+}
+
+// get returns the cached document of the query, adding an empty entry on a miss.
+func (c *documentCache) get(queryString string) *validatedDocument {
+	if d, ok := c.cache.Get(queryString); ok {
+		c.hits.Add(1)
+		return d
+	}
+	c.misses.Add(1)
+	d := &validatedDocument{}
+	c.cache.Add(queryString, d)
+	return d
+}
+
+// validatedDocument holds a parsed document and the result of its variable-independent
+// validation against a schema. Both are computed on first use.
+type validatedDocument struct {
+	mu        sync.Mutex
+	schema    *Schema
+	doc       *ast.ExecutableDefinition
+	parseErrs []*errors.QueryError
+	validated bool
+	errs      []*errors.QueryError
+}
+
+// document returns the parsed query validated against the schema, ignoring variables.
+func (d *validatedDocument) document(s *Schema, queryString string) (*ast.ExecutableDefinition, []*errors.QueryError) {
+	doc, errs := d.parse(s, queryString)
+	if errs != nil {
+		return nil, errs
+	}
+	return doc, d.validateDocument(s, doc)
+}
+
+// parse returns the parsed query, checking the limits of the schema.
+func (d *validatedDocument) parse(s *Schema, queryString string) (*ast.ExecutableDefinition, []*errors.QueryError) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.schema == s {
+		return d.doc, d.parseErrs
+	}
+
+	d.schema, d.doc, d.parseErrs, d.validated, d.errs = s, nil, nil, false, nil
+	if s.maxQueryLength > 0 && len(queryString) > s.maxQueryLength {
+		d.parseErrs = []*errors.QueryError{errors.Errorf("query length %d exceeds the maximum allowed query length of %d bytes", len(queryString), s.maxQueryLength)}
+		return nil, d.parseErrs
+	}
+	d.doc, d.parseErrs = s.parseQuery(queryString)
+	return d.doc, d.parseErrs
+}
+
+// validateDocument returns the errors of the validation of the parsed document against the
+// schema, ignoring variables.
+func (d *validatedDocument) validateDocument(s *Schema, doc *ast.ExecutableDefinition) []*errors.QueryError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.schema != s || d.doc != doc {
+		// The document was parsed again for another schema in the meantime.
+		return validation.ValidateDocument(s.schema, doc, s.maxDepth, s.overlapPairLimit, s.validateDeprecated)
+	}
+	if !d.validated {
+		d.errs = validation.ValidateDocument(s.schema, doc, s.maxDepth, s.overlapPairLimit, s.validateDeprecated)
+		d.validated = true
+	}
+	return d.errs
+}
+
+// validate returns the parsed query validated against the schema and the variables. As in
+// parseAndValidate, the plugins see the document between parsing and validation, and only the
+// validation is traced.
+func (d *validatedDocument) validate(ctx context.Context, s *Schema, queryString string, variables map[string]any) (*ast.ExecutableDefinition, []*errors.QueryError) {
+	doc, errs := d.parse(s, queryString)
+	if errs != nil {
+		return nil, errs
+	}
+	if errs := s.afterParse(ctx, doc); errs != nil {
+		return nil, errs
+	}
+
+	validationFinish := s.validationTracer.TraceValidation(ctx)
+	errs = d.validateDocument(s, doc)
+	if len(errs) == 0 {
+		errs = validation.ValidateVariables(s.schema, doc, variables)
+	}
+	validationFinish(errs)
+	if errs = s.afterValidation(ctx, doc, errs); len(errs) != 0 {
+		return nil, errs
+	}
+	return doc, nil
+}
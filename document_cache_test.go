@@ -0,0 +1,95 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+)
+
+func TestDocumentCache(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.DocumentCache(2))
+	ctx := context.Background()
+
+	const q = `query($id: ID!) { character(id: $id) { name } }`
+	for _, step := range []struct {
+		name      string
+		query     string
+		variables map[string]any
+		want      string
+		stats     graphql.DocumentCacheStats
+	}{
+		{
+			name:      "miss",
+			query:     q,
+			variables: map[string]any{"id": "1000"},
+			want:      `{"data":{"character":{"name":"Luke Skywalker"}}}`,
+			stats:     graphql.DocumentCacheStats{Misses: 1, Size: 1},
+		},
+		{
+			name:      "hit",
+			query:     q,
+			variables: map[string]any{"id": "1001"},
+			want:      `{"data":{"character":{"name":"Darth Vader"}}}`,
+			stats:     graphql.DocumentCacheStats{Hits: 1, Misses: 1, Size: 1},
+		},
+		{
+			name:  "hit validates variables",
+			query: q,
+			want:  `{"errors":[{"message":"Variable \"id\" has invalid value null.\nExpected type \"ID!\", found null.","locations":[{"line":1,"column":7}]}]}`,
+			stats: graphql.DocumentCacheStats{Hits: 2, Misses: 1, Size: 1},
+		},
+		{
+			name:  "invalid document",
+			query: `{ hero { unknown } }`,
+			want:  `{"errors":[{"message":"Cannot query field \"unknown\" on type \"Character\".","locations":[{"line":1,"column":10}]}]}`,
+			stats: graphql.DocumentCacheStats{Hits: 2, Misses: 2, Size: 2},
+		},
+		{
+			name:  "cached invalid document",
+			query: `{ hero { unknown } }`,
+			want:  `{"errors":[{"message":"Cannot query field \"unknown\" on type \"Character\".","locations":[{"line":1,"column":10}]}]}`,
+			stats: graphql.DocumentCacheStats{Hits: 3, Misses: 2, Size: 2},
+		},
+		{
+			name:  "eviction",
+			query: `{ hero { name } }`,
+			want:  `{"data":{"hero":{"name":"R2-D2"}}}`,
+			stats: graphql.DocumentCacheStats{Hits: 3, Misses: 3, Size: 2},
+		},
+	} {
+		got := responseJSON(t, schema.Exec(ctx, step.query, "", step.variables))
+		if got != step.want {
+			t.Errorf("%s: wrong response\nwant: %s\ngot:  %s", step.name, step.want, got)
+		}
+		if stats := schema.DocumentCacheStats(); stats != step.stats {
+			t.Errorf("%s: wrong stats\nwant: %+v\ngot:  %+v", step.name, step.stats, stats)
+		}
+	}
+
+	clone := schema.MustClone(&starwars.Resolver{}, graphql.MaxDepth(1))
+	want := `{"errors":[{"message":"Field \"name\" has depth 2 that exceeds max depth 1","locations":[{"line":1,"column":10}]}]}`
+	if got := responseJSON(t, clone.Exec(ctx, `{ hero { name } }`, "", nil)); got != want {
+		t.Errorf("clone: wrong response\nwant: %s\ngot:  %s", want, got)
+	}
+	if stats := clone.DocumentCacheStats(); stats != (graphql.DocumentCacheStats{Misses: 1, Size: 1}) {
+		t.Errorf("clone: wrong stats %+v", stats)
+	}
+}
+
+func TestDocumentCacheDisabled(t *testing.T) {
+	for name, opts := range map[string][]graphql.SchemaOpt{
+		"no option": nil,
+		"zero size": {graphql.DocumentCache(0)},
+		"negative":  {graphql.DocumentCache(-1)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, opts...)
+			schema.Exec(context.Background(), `{ hero { name } }`, "", nil)
+			if stats := schema.DocumentCacheStats(); stats != (graphql.DocumentCacheStats{}) {
+				t.Errorf("want zero stats, got %+v", stats)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package graphql
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/log"
+)
+
+// ErrorPresenter converts the errors returned by resolvers, including the resolvers of
+// subscriptions, before they are added to the response, e.g. to hide internal details from clients:
+//
+//	presenter := func(ctx context.Context, err error) *errors.QueryError {
+//		if errors.Is(err, sql.ErrNoRows) {
+//			return &errors.QueryError{Message: "not found"}
+//		}
+//		return nil
+//	}
+//
+// The path and locations of the presented error default to the ones of the field, and its
+// ResolverError defaults to the original error. If the presenter returns nil, the error is
+// presented as usual, i.e. with the message of the error and its extensions. Panics are presented
+// by the [PanicHandler] instead. See [MaskErrors] for a presenter which hides unexpected errors.
+func ErrorPresenter(presenter func(ctx context.Context, err error) *qerrors.QueryError) SchemaOpt {
+	return func(s *Schema) {
+		s.errorPresenter = presenter
+	}
+}
+
+// PublicError is implemented by errors whose messages may be shown to clients. [MaskErrors] passes
+// errors through unchanged if their Public method returns true.
+type PublicError interface {
+	error
+	Public() bool
+}
+
+// MaskErrors returns an error presenter for [ErrorPresenter], which replaces the errors returned by
+// resolvers with the message "internal server error" and a random correlation ID in the
+// "correlationId" extension. The original error is passed to logf together with the correlation ID,
+// so that it can be found in the logs. If logf is nil, the error is logged with the [Logger] of the
+// schema.
+//
+// Errors which are a *errors.QueryError, or which implement [PublicError] and return true, are
+// passed through unchanged. Wrapped errors are masked, since the message of the wrapping error may
+// contain internal details. Errors of timed out fields are public.
+func MaskErrors(logf func(ctx context.Context, correlationID string, err error)) func(ctx context.Context, err error) *qerrors.QueryError {
+	if logf == nil {
+		logf = logMaskedError
+	}
+	return func(ctx context.Context, err error) *qerrors.QueryError {
+		if qErr, ok := err.(*qerrors.QueryError); ok {
+			return qErr
+		}
+		if public, ok := err.(PublicError); ok && public.Public() {
+			return nil
+		}
+		id := correlationID()
+		logf(ctx, id, err)
+		return &qerrors.QueryError{
+			Message:    "internal server error",
+			Extensions: map[string]any{"correlationId": id},
+		}
+	}
+}
+
+// logMaskedError logs an error masked by [MaskErrors] with the logger of the schema.
+func logMaskedError(ctx context.Context, correlationID string, err error) {
+	logger, _ := ctx.Value(loggerKey{}).(log.Logger)
+	logf(ctx, logger, "graphql: resolver error (correlation ID %s): %v", correlationID, err)
+}
+
+// correlationID returns a random ID for correlating masked errors with the logs.
+func correlationID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:]) // never returns an error
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,175 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+const errorPresenterSchema = `
+	type Query {
+		internal: String
+		notFound: String
+		public: String
+		shared: String
+		slow: String
+		wrapped: String
+		wrappedPublic: String
+	}
+	type Subscription {
+		events: String!
+	}
+`
+
+var (
+	errInternal    = errors.New("pq: relation \"users\" does not exist")
+	errNotFound    = errors.New("not found")
+	errSharedQuery = &qerrors.QueryError{Message: "shared", Extensions: map[string]any{"code": "SHARED"}}
+)
+
+type publicError struct{ msg string }
+
+func (e *publicError) Error() string              { return e.msg }
+func (e *publicError) Public() bool               { return true }
+func (e *publicError) Extensions() map[string]any { return map[string]any{"code": "PUBLIC"} }
+
+type errorPresenterResolver struct{}
+
+func (errorPresenterResolver) Internal() (*string, error) { return nil, errInternal }
+func (errorPresenterResolver) NotFound() (*string, error) { return nil, errNotFound }
+func (errorPresenterResolver) Public() (*string, error) {
+	return nil, &publicError{msg: "invalid input"}
+}
+func (errorPresenterResolver) Shared() (*string, error) { return nil, errSharedQuery }
+func (errorPresenterResolver) Slow(ctx context.Context) (*string, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+func (errorPresenterResolver) Wrapped() (*string, error) {
+	return nil, fmt.Errorf("secret %w", errSharedQuery)
+}
+func (errorPresenterResolver) WrappedPublic() (*string, error) {
+	return nil, fmt.Errorf("secret %w", &publicError{msg: "invalid input"})
+}
+func (errorPresenterResolver) Events() (<-chan string, error) { return nil, errInternal }
+
+func TestErrorPresenter(t *testing.T) {
+	presenter := func(ctx context.Context, err error) *qerrors.QueryError {
+		if errors.Is(err, errNotFound) {
+			return &qerrors.QueryError{Message: "nothing here", Extensions: map[string]any{"code": "NOT_FOUND"}}
+		}
+		return nil
+	}
+	s := graphql.MustParseSchema(errorPresenterSchema, &errorPresenterResolver{}, graphql.ErrorPresenter(presenter))
+	res := s.Exec(context.Background(), `{ internal notFound }`, "", nil)
+	slices.SortFunc(res.Errors, func(a, b *qerrors.QueryError) int {
+		return strings.Compare(a.Path[0].(string), b.Path[0].(string))
+	})
+	got, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"errors":[` +
+		`{"message":"pq: relation \"users\" does not exist","locations":[{"line":1,"column":3}],"path":["internal"]},` +
+		`{"message":"nothing here","locations":[{"line":1,"column":12}],"path":["notFound"],"extensions":{"code":"NOT_FOUND"}}` +
+		`],"data":{"internal":null,"notFound":null}}`
+	if string(got) != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+	for _, qErr := range res.Errors {
+		if qErr.ResolverError == nil {
+			t.Errorf("error %q has no resolver error", qErr.Message)
+		}
+	}
+}
+
+func TestMaskErrors(t *testing.T) {
+	var logged []string
+	logf := func(ctx context.Context, id string, err error) {
+		logged = append(logged, id+": "+err.Error())
+	}
+	s := graphql.MustParseSchema(errorPresenterSchema, &errorPresenterResolver{},
+		graphql.ErrorPresenter(graphql.MaskErrors(logf)),
+		graphql.FieldTimeouts(map[string]time.Duration{"Query.slow": time.Millisecond}),
+	)
+	res := s.Exec(context.Background(), `{ internal public shared slow }`, "", nil)
+	if len(res.Errors) != 4 {
+		t.Fatalf("got errors %v", res.Errors)
+	}
+	byPath := make(map[string]*qerrors.QueryError)
+	for _, qErr := range res.Errors {
+		byPath[qErr.Path[0].(string)] = qErr
+	}
+
+	masked := byPath["internal"]
+	id, _ := masked.Extensions["correlationId"].(string)
+	if masked.Message != "internal server error" || len(id) != 32 {
+		t.Errorf("got masked error %q with extensions %v", masked.Message, masked.Extensions)
+	}
+	if len(logged) != 1 || logged[0] != id+": "+errInternal.Error() {
+		t.Errorf("got logged errors %q", logged)
+	}
+	if !errors.Is(masked.ResolverError, errInternal) {
+		t.Errorf("got resolver error %v", masked.ResolverError)
+	}
+
+	if public := byPath["public"]; public.Message != "invalid input" || public.Extensions["code"] != "PUBLIC" {
+		t.Errorf("got public error %q with extensions %v", public.Message, public.Extensions)
+	}
+	if shared := byPath["shared"]; shared.Message != "shared" || shared.Extensions["code"] != "SHARED" || len(shared.Locations) != 1 {
+		t.Errorf("got shared error %+v", shared)
+	}
+	if errSharedQuery.Path != nil || errSharedQuery.Locations != nil {
+		t.Errorf("shared query error was modified: %+v", errSharedQuery)
+	}
+	if slow := byPath["slow"]; slow.Message != "graphql: field Query.slow timed out after 1ms" {
+		t.Errorf("got timeout error %q", slow.Message)
+	}
+}
+
+func TestMaskErrorsWrapped(t *testing.T) {
+	logger := &messageLogger{}
+	s := graphql.MustParseSchema(errorPresenterSchema, &errorPresenterResolver{},
+		graphql.ErrorPresenter(graphql.MaskErrors(nil)),
+		graphql.Logger(logger),
+	)
+	res := s.Exec(context.Background(), `{ wrapped wrappedPublic }`, "", nil)
+	if len(res.Errors) != 2 {
+		t.Fatalf("got errors %v", res.Errors)
+	}
+	for _, qErr := range res.Errors {
+		if qErr.Message != "internal server error" {
+			t.Errorf("wrapped error at %v was not masked: %q", qErr.Path, qErr.Message)
+		}
+	}
+	if len(logger.messages) != 2 {
+		t.Fatalf("got logged messages %q", logger.messages)
+	}
+	for _, msg := range logger.messages {
+		if !strings.HasPrefix(msg, "graphql: resolver error (correlation ID ") || !strings.Contains(msg, "secret") {
+			t.Errorf("got logged message %q", msg)
+		}
+	}
+}
+
+func TestMaskErrorsSubscription(t *testing.T) {
+	s := graphql.MustParseSchema(errorPresenterSchema, &errorPresenterResolver{},
+		graphql.ErrorPresenter(graphql.MaskErrors(func(context.Context, string, error) {})),
+	)
+	c, err := s.Subscribe(context.Background(), `subscription { events }`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := (<-c).(*graphql.Response)
+	if len(res.Errors) != 1 || res.Errors[0].Message != "internal server error" {
+		t.Errorf("got errors %v", res.Errors)
+	}
+}
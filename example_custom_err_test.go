@@ -107,6 +107,12 @@ func Example_customErrors() {
 	//   "errors": [
 	//     {
 	//       "message": "error [NotFound]: Product not found.",
+	//       "locations": [
+	//         {
+	//           "line": 3,
+	//           "column": 3
+	//         }
+	//       ],
 	//       "path": [
 	//         "product"
 	//       ],
@@ -0,0 +1,83 @@
+package graphql
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+type extensionsKey struct{}
+
+// extensionCollector collects the response extensions set during the execution of a request.
+type extensionCollector struct {
+	mu     sync.Mutex
+	values map[string]any
+}
+
+// SetExtension sets an entry of the extensions of the response of the request executing with ctx,
+// e.g. to report rate limits, cache hints or debug information. It may be called concurrently by
+// resolvers, field middleware and directive handlers. Writes to the same key are merged:
+//
+//   - if both values are a map[string]any, the entries of value are merged into the present map,
+//     recursively following these rules,
+//   - if both values are a []any, the items of value are appended to the present slice,
+//   - otherwise value replaces the present value.
+//
+// Entries are merged into the extensions reported by the library, such as "cost", in the same
+// way. SetExtension does nothing if ctx does not belong to the execution of a query or mutation.
+// Entries set by deferred fragments and streamed list items after the initial response are not
+// reported.
+func SetExtension(ctx context.Context, key string, value any) {
+	c, ok := ctx.Value(extensionsKey{}).(*extensionCollector)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.values == nil {
+		c.values = make(map[string]any)
+	}
+	c.values[key] = mergeExtension(c.values[key], value)
+}
+
+// withExtensionCollector returns a context with a new collector of response extensions.
+func withExtensionCollector(ctx context.Context) (context.Context, *extensionCollector) {
+	c := &extensionCollector{}
+	return context.WithValue(ctx, extensionsKey{}, c), c
+}
+
+// merge merges the collected entries into the extensions and returns the extensions.
+func (c *extensionCollector) merge(extensions map[string]any) map[string]any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.values) == 0 {
+		return extensions
+	}
+	if extensions == nil {
+		extensions = make(map[string]any, len(c.values))
+	}
+	for key, value := range c.values {
+		extensions[key] = mergeExtension(extensions[key], value)
+	}
+	return extensions
+}
+
+// mergeExtension merges value into the present value of an extension entry. Maps and slices
+// passed to SetExtension are not modified.
+func mergeExtension(present, value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		if present, ok := present.(map[string]any); ok {
+			merged := maps.Clone(present)
+			for k, v := range value {
+				merged[k] = mergeExtension(merged[k], v)
+			}
+			return merged
+		}
+	case []any:
+		if present, ok := present.([]any); ok {
+			return append(present[:len(present):len(present)], value...)
+		}
+	}
+	return value
+}
@@ -0,0 +1,97 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const extensionsSchema = `
+	type Query {
+		items: [Item!]!
+		debug: String!
+	}
+	type Item {
+		id: Int!
+	}
+`
+
+type extensionsResolver struct{}
+
+func (extensionsResolver) Items() []*extensionsItem {
+	items := make([]*extensionsItem, 20)
+	for i := range items {
+		items[i] = &extensionsItem{id: int32(i)}
+	}
+	return items
+}
+
+func (extensionsResolver) Debug(ctx context.Context) string {
+	graphql.SetExtension(ctx, "debug", "first")
+	graphql.SetExtension(ctx, "debug", "second")
+	graphql.SetExtension(ctx, "cost", map[string]any{"actualQueryCost": 3})
+	return "debug"
+}
+
+type extensionsItem struct {
+	id int32
+}
+
+func (i *extensionsItem) ID(ctx context.Context) int32 {
+	graphql.SetExtension(ctx, "cacheHints", map[string]any{fmt.Sprintf("Item.%d", i.id): 60})
+	graphql.SetExtension(ctx, "loaded", []any{i.id})
+	return i.id
+}
+
+func TestSetExtension(t *testing.T) {
+	s := graphql.MustParseSchema(extensionsSchema, &extensionsResolver{}, graphql.MaxCost(100))
+	res := s.Exec(context.Background(), `{ items { id } debug }`, "", nil)
+	if len(res.Errors) != 0 {
+		t.Fatal(res.Errors)
+	}
+
+	hints, _ := res.Extensions["cacheHints"].(map[string]any)
+	if len(hints) != 20 {
+		t.Errorf("got %d cache hints, want 20", len(hints))
+	}
+	loaded, _ := res.Extensions["loaded"].([]any)
+	ids := make([]int, 0, len(loaded))
+	for _, id := range loaded {
+		ids = append(ids, int(id.(int32)))
+	}
+	slices.Sort(ids)
+	if len(ids) != 20 || ids[0] != 0 || ids[19] != 19 {
+		t.Errorf("got loaded ids %v, want 0 to 19", ids)
+	}
+	if got := res.Extensions["debug"]; got != "second" {
+		t.Errorf("got debug extension %v, want second", got)
+	}
+	cost, err := json.Marshal(res.Extensions["cost"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"actualQueryCost":3,"maximumAvailable":100,"requestedQueryCost":10}`; string(cost) != want {
+		t.Errorf("got cost extension %s, want %s", cost, want)
+	}
+
+	// Every request collects its own extensions.
+	res = s.Exec(context.Background(), `{ debug }`, "", nil)
+	if _, ok := res.Extensions["cacheHints"]; ok {
+		t.Errorf("got extensions of another request: %v", res.Extensions)
+	}
+}
+
+func TestSetExtensionWithoutCost(t *testing.T) {
+	// SetExtension does nothing outside of an execution.
+	graphql.SetExtension(context.Background(), "key", "value")
+
+	s := graphql.MustParseSchema(extensionsSchema, &extensionsResolver{})
+	res := s.Exec(context.Background(), `{ items { id } }`, "", nil)
+	if loaded, _ := res.Extensions["loaded"].([]any); len(loaded) != 20 || len(res.Extensions) != 2 {
+		t.Errorf("got extensions %v", res.Extensions)
+	}
+}
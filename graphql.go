@@ -51,14 +51,25 @@ func ParseSchema(schemaString string, resolver any, opts ...SchemaOpt) (*Schema,
 		}
 	}
 
+	if s.incrementalDelivery {
+		if err := schema.AddIncrementalDirectives(s.schema); err != nil {
+			return nil, err
+		}
+	}
 	if err := schema.Parse(s.schema, schemaString, s.useStringDescriptions); err != nil {
 		return nil, err
 	}
 	if err := s.validateSchema(); err != nil {
 		return nil, err
 	}
+	if err := s.validateFieldTimeouts(); err != nil {
+		return nil, err
+	}
+	if err := s.validateDirectiveHandlers(); err != nil {
+		return nil, err
+	}
 
-	r, err := resolvable.ApplyResolver(s.schema, resolver, s.useFieldResolvers)
+	r, err := resolvable.ApplyResolver(s.schema, resolver, s.useFieldResolvers, s.resolvableDirectives())
 	if err != nil {
 		return nil, err
 	}
@@ -111,13 +122,35 @@ func (s *Schema) Clone(resolver any, opts ...SchemaOpt) (*Schema, error) {
 		disableMemoryPooling:     s.disableMemoryPooling,
 		overlapPairLimit:         s.overlapPairLimit,
 		validateDeprecated:       s.validateDeprecated,
+		persistedQueries:         s.persistedQueries,
+		trustedDocuments:         s.trustedDocuments,
+		fieldMiddleware:          s.fieldMiddleware,
+		skipTrivialMiddleware:    s.skipTrivialMiddleware,
+		directiveHandlers:        s.directiveHandlers,
+		strictDirectives:         s.strictDirectives,
+		maxCost:                  s.maxCost,
+		maxTokens:                s.maxTokens,
+		limits:                   s.limits,
+		executionTimeout:         s.executionTimeout,
+		fieldTimeouts:            s.fieldTimeouts,
+		partialData:              s.partialData,
+		plugins:                  s.plugins,
+		errorPresenter:           s.errorPresenter,
+		incrementalDelivery:      s.incrementalDelivery,
+	}
+	if s.documentCache != nil {
+		// Cached documents are validated against a single schema.
+		clone.documentCache = newDocumentCache(s.documentCache.size)
 	}
 
 	for _, opt := range opts {
 		opt(clone)
 	}
+	if err := clone.validateFieldTimeouts(); err != nil {
+		return nil, err
+	}
 
-	res, err := resolvable.ApplyResolver(clone.schema, resolver, clone.useFieldResolvers)
+	res, err := resolvable.ApplyResolver(clone.schema, resolver, clone.useFieldResolvers, clone.resolvableDirectives())
 	if err != nil {
 		return nil, err
 	}
@@ -165,7 +198,7 @@ func (s *Schema) ApplyResolver(resolver any) error {
 		return fmt.Errorf("resolver already applied to schema")
 	}
 
-	res, err := resolvable.ApplyResolver(s.schema, resolver, s.useFieldResolvers)
+	res, err := resolvable.ApplyResolver(s.schema, resolver, s.useFieldResolvers, s.resolvableDirectives())
 	if err != nil {
 		return err
 	}
@@ -196,6 +229,22 @@ type Schema struct {
 	maxPooledBufferCapacity  int
 	overlapPairLimit         int
 	validateDeprecated       bool
+	persistedQueries         PersistedQueryStore
+	trustedDocuments         *trustedDocuments
+	documentCache            *documentCache
+	fieldMiddleware          []FieldMiddleware
+	skipTrivialMiddleware    bool
+	directiveHandlers        map[string]DirectiveHandler
+	strictDirectives         bool
+	maxCost                  int
+	maxTokens                int
+	limits                   validation.Limits
+	executionTimeout         time.Duration
+	fieldTimeouts            map[string]time.Duration
+	partialData              bool
+	plugins                  []Plugin
+	errorPresenter           func(ctx context.Context, err error) *errors.QueryError
+	incrementalDelivery      bool
 }
 
 // AST returns the abstract syntax tree of the GraphQL schema definition.
@@ -264,6 +313,46 @@ func MaxDepth(n int) SchemaOpt {
 	}
 }
 
+// MaxAliases specifies the maximum number of aliased fields in an operation, including the fields
+// of the fragments it spreads. The default is 0 which disables the limit.
+func MaxAliases(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.limits.MaxAliases = n
+	}
+}
+
+// MaxRootFields specifies the maximum number of root fields in an operation, including the root
+// fields of the fragments it spreads. The default is 0 which disables the limit.
+func MaxRootFields(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.limits.MaxRootFields = n
+	}
+}
+
+// MaxTokens specifies the maximum number of tokens in a query. Parsing stops once the limit is
+// exceeded. The default is 0 which disables the limit.
+func MaxTokens(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.maxTokens = n
+	}
+}
+
+// MaxDirectivesPerField specifies the maximum number of directives applied to a field in a query.
+// The default is 0 which disables the limit.
+func MaxDirectivesPerField(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.limits.MaxDirectivesPerField = n
+	}
+}
+
+// MaxFragments specifies the maximum number of fragment definitions and inline fragments in a
+// query. The default is 0 which disables the limit.
+func MaxFragments(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.limits.MaxFragments = n
+	}
+}
+
 // MaxParallelism specifies the maximum number of resolvers per request allowed to run in parallel. The default is 10.
 func MaxParallelism(n int) SchemaOpt {
 	return func(s *Schema) {
@@ -309,13 +398,24 @@ func ValidationTracer(tracer tracer.LegacyValidationTracer) SchemaOpt { //nolint
 	}
 }
 
-// Logger is used to log panics during query execution. It defaults to [log.DefaultLogger].
+// Logger is used to log panics during query execution. It defaults to [log.DefaultLogger]. If the
+// logger implements [log.MessageLogger], it also logs the messages of the schema, e.g. the reports
+// of [TrustedDocumentsLogOnly], which are otherwise logged by [log.DefaultLogger].
 func Logger(logger log.Logger) SchemaOpt {
 	return func(s *Schema) {
 		s.logger = logger
 	}
 }
 
+// logf logs a message with logger, or with [log.DefaultLogger] if logger only logs panics.
+func logf(ctx context.Context, logger log.Logger, format string, args ...any) {
+	ml, ok := logger.(log.MessageLogger)
+	if !ok {
+		ml = &log.DefaultLogger{}
+	}
+	ml.Logf(ctx, format, args...)
+}
+
 // PanicHandler is used to customize the panic errors during query execution.
 // It defaults to [errors.DefaultPanicHandler].
 func PanicHandler(panicHandler errors.PanicHandler) SchemaOpt {
@@ -380,9 +480,9 @@ func (s *Schema) Validate(queryString string) []*errors.QueryError {
 
 // ValidateWithVariables validates the given query with the schema and the input variables.
 func (s *Schema) ValidateWithVariables(queryString string, variables map[string]any) []*errors.QueryError {
-	doc, qErr := query.Parse(queryString)
-	if qErr != nil {
-		return []*errors.QueryError{qErr}
+	doc, errs := s.parseQuery(queryString)
+	if errs != nil {
+		return errs
 	}
 
 	if len(doc.Operations) == 0 {
@@ -399,43 +499,174 @@ func (s *Schema) Exec(ctx context.Context, queryString string, operationName str
 	if !s.res.QueryResolver.IsValid() {
 		panic("schema created without resolver, can not exec")
 	}
-	return s.exec(ctx, queryString, operationName, variables, s.res)
+	return s.ExecRequest(ctx, &Request{Query: queryString, OperationName: operationName, Variables: variables})
+}
+
+// Request holds the parameters of a GraphQL request. Unlike the arguments of [Schema.Exec] it
+// carries the request extensions and the document ID, which enable protocol features such as
+// automatic persisted queries (see [AutomaticPersistedQueries]) and trusted documents (see
+// [TrustedDocuments]).
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+	Extensions    map[string]any `json:"extensions"`
+
+	// DocumentID references a document of the trusted document manifest instead of sending
+	// the query.
+	DocumentID string `json:"documentId"`
+
+	resolved *resolvedDocument
+}
+
+// ExecRequest executes the given request with the schema's resolver like [Schema.Exec]. It panics
+// if the schema was created without a resolver.
+func (s *Schema) ExecRequest(ctx context.Context, req *Request) *Response {
+	if !s.res.QueryResolver.IsValid() {
+		panic("schema created without resolver, can not exec")
+	}
+	queryString, doc, errs := s.resolveDocument(ctx, req)
+	if len(errs) != 0 {
+		return &Response{Errors: errs}
+	}
+	return s.execDocument(ctx, queryString, doc, req.OperationName, req.Variables, s.res)
+}
+
+// requestDocument returns the validated document of the request and its query string.
+func (s *Schema) requestDocument(ctx context.Context, req *Request) (string, *ast.ExecutableDefinition, []*errors.QueryError) {
+	if s.trustedDocuments != nil {
+		q, errs := s.trustedDocument(ctx, req)
+		if len(errs) != 0 {
+			return "", nil, errs
+		}
+		if q != nil {
+			doc, errs := q.validate(ctx, s, req.Variables)
+			return q.Query, doc, errs
+		}
+	}
+	if req.DocumentID != "" {
+		return "", nil, []*errors.QueryError{errors.Errorf("document ids are not supported by the schema")}
+	}
+	if ext, ok := req.Extensions["persistedQuery"]; ok {
+		return s.persistedDocument(ctx, req, ext)
+	}
+	doc, errs := s.parseAndValidate(ctx, req.Query, req.Variables)
+	return req.Query, doc, errs
+}
+
+// OperationType returns the type of the operation of req selected by its operation name, so that
+// transports can decide how to execute it, e.g. with [Schema.SubscribeRequest]. The document is
+// resolved like by [Schema.ExecRequest], i.e. stored documents are looked up and queries are parsed
+// and validated, and kept in req, so that executing req afterwards reuses it. Therefore req must
+// not be used concurrently or modified until it is executed. It returns false if the document is
+// not found, is invalid or does not contain the operation, in which case executing the request
+// reports the error.
+func (s *Schema) OperationType(ctx context.Context, req *Request) (ast.OperationType, bool) {
+	queryString, doc, errs := s.resolveDocument(ctx, req)
+	req.resolved = &resolvedDocument{
+		schema:      s,
+		query:       req.Query,
+		documentID:  req.DocumentID,
+		queryString: queryString,
+		doc:         doc,
+		errs:        errs,
+	}
+	if len(errs) != 0 {
+		return "", false
+	}
+	op, err := getOperation(doc, req.OperationName)
+	if err != nil {
+		return "", false
+	}
+	return op.Type, true
+}
+
+// resolvedDocument is the document of a request resolved by [Schema.OperationType].
+type resolvedDocument struct {
+	schema      *Schema
+	query       string
+	documentID  string
+	queryString string
+	doc         *ast.ExecutableDefinition
+	errs        []*errors.QueryError
+}
+
+// resolveDocument returns the document of the request resolved by [Schema.OperationType], or
+// resolves it with requestDocument.
+func (s *Schema) resolveDocument(ctx context.Context, req *Request) (string, *ast.ExecutableDefinition, []*errors.QueryError) {
+	if r := req.resolved; r != nil && r.schema == s && r.query == req.Query && r.documentID == req.DocumentID {
+		return r.queryString, r.doc, r.errs
+	}
+	return s.requestDocument(ctx, req)
 }
 
 func (s *Schema) exec(ctx context.Context, queryString string, operationName string, variables map[string]any, res *resolvable.Schema) *Response {
+	doc, errs := s.parseAndValidate(ctx, queryString, variables)
+	if len(errs) != 0 {
+		return &Response{Errors: errs}
+	}
+	return s.execDocument(ctx, queryString, doc, operationName, variables, res)
+}
+
+// parseAndValidate parses the query and validates it with the given variables.
+func (s *Schema) parseAndValidate(ctx context.Context, queryString string, variables map[string]any) (*ast.ExecutableDefinition, []*errors.QueryError) {
 	if s.maxQueryLength > 0 && len(queryString) > s.maxQueryLength {
-		return &Response{Errors: []*errors.QueryError{errors.Errorf("query length %d exceeds the maximum allowed query length of %d bytes", len(queryString), s.maxQueryLength)}}
+		return nil, []*errors.QueryError{errors.Errorf("query length %d exceeds the maximum allowed query length of %d bytes", len(queryString), s.maxQueryLength)}
 	}
-	doc, qErr := query.Parse(queryString)
-	if qErr != nil {
-		return &Response{Errors: []*errors.QueryError{qErr}}
+	if s.documentCache != nil {
+		return s.documentCache.get(queryString).validate(ctx, s, queryString, variables)
+	}
+	doc, errs := s.parseQuery(queryString)
+	if errs != nil {
+		return nil, errs
+	}
+	if errs := s.afterParse(ctx, doc); errs != nil {
+		return nil, errs
 	}
 
 	validationFinish := s.validationTracer.TraceValidation(ctx)
-	errs := validation.Validate(s.schema, doc, variables, s.maxDepth, s.overlapPairLimit, s.validateDeprecated)
+	errs = validation.Validate(s.schema, doc, variables, s.maxDepth, s.overlapPairLimit, s.validateDeprecated)
 	validationFinish(errs)
-	if len(errs) != 0 {
-		return &Response{Errors: errs}
+	return doc, s.afterValidation(ctx, doc, errs)
+}
+
+// parseQuery parses the query and checks the limits of its operations, which protect the
+// validation from expensive documents.
+func (s *Schema) parseQuery(queryString string) (*ast.ExecutableDefinition, []*errors.QueryError) {
+	doc, qErr := query.ParseWithMaxTokens(queryString, s.maxTokens)
+	if qErr != nil {
+		return nil, []*errors.QueryError{qErr}
+	}
+	if errs := validation.ValidateLimits(doc, s.limits); len(errs) > 0 {
+		return nil, errs
 	}
+	return doc, nil
+}
 
+// execDocument executes an operation of a validated document.
+func (s *Schema) execDocument(ctx context.Context, queryString string, doc *ast.ExecutableDefinition, operationName string, variables map[string]any, res *resolvable.Schema) *Response {
 	op, err := getOperation(doc, operationName)
 	if err != nil {
 		return &Response{Errors: []*errors.QueryError{errors.Errorf("%s", err)}}
 	}
+	resp, _ := s.execOperation(ctx, queryString, doc, op, variables, res, false)
+	return resp
+}
 
-	// If the optional "operationName" POST parameter is not provided then
-	// use the query's operation name for improved tracing.
-	if operationName == "" {
-		operationName = op.Name.Name
-	}
+// execOperation executes an operation of a validated document. If incremental is true, deferred
+// fragments and streamed list items are delivered on the returned channel.
+func (s *Schema) execOperation(ctx context.Context, queryString string, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]any, res *resolvable.Schema, incremental bool) (*Response, <-chan *exec.SubsequentResult) {
+	// The operation name is used for tracing even if the optional "operationName"
+	// POST parameter was not provided.
+	operationName := op.Name.Name
 
 	// Subscriptions are not valid in Exec. Use schema.Subscribe() instead.
 	if op.Type == query.Subscription {
-		return &Response{Errors: []*errors.QueryError{{Message: "graphql-ws protocol header is missing"}}}
+		return &Response{Errors: []*errors.QueryError{{Message: "graphql-ws protocol header is missing"}}}, nil
 	}
 	if op.Type == query.Mutation {
 		if _, ok := s.schema.RootOperationTypes["mutation"]; !ok {
-			return &Response{Errors: []*errors.QueryError{{Message: "no mutations are offered by the schema"}}}
+			return &Response{Errors: []*errors.QueryError{{Message: "no mutations are offered by the schema"}}}, nil
 		}
 	}
 
@@ -449,6 +680,26 @@ func (s *Schema) exec(ctx context.Context, queryString string, operationName str
 		}
 	}
 
+	if !incremental {
+		// The execution timeout of incremental executions is applied by ExecIncremental, since
+		// the execution continues after execOperation returns.
+		var cancel context.CancelFunc
+		ctx, cancel = s.withExecutionTimeout(ctx)
+		defer cancel()
+	}
+
+	ctx = withOperation(ctx, op)
+	ctx = withLogger(ctx, s.logger)
+	ctx, extensions, costErrs := s.checkCost(ctx, doc, op, variables)
+	if costErrs != nil {
+		return &Response{Errors: costErrs, Extensions: extensions}, nil
+	}
+	ctx, errs := s.beforeExecution(ctx, doc, op, variables)
+	if errs != nil {
+		return &Response{Errors: errs, Extensions: extensions}, nil
+	}
+	ctx, collector := withExtensionCollector(ctx)
+
 	r := &exec.Request{
 		Request: selected.Request{
 			Doc:                doc,
@@ -463,23 +714,39 @@ func (s *Schema) exec(ctx context.Context, queryString string, operationName str
 		DisableFieldSelections:  s.disableFieldSelections,
 		DisableMemoryPooling:    s.disableMemoryPooling,
 		MaxPooledBufferCapacity: s.maxPooledBufferCapacity,
+		FieldMiddleware:         s.execFieldMiddleware(),
+		SkipTrivialMiddleware:   s.skipTrivialMiddleware,
+		DirectiveHandler:        s.execDirectiveHandler(),
+		FieldTimeouts:           s.execFieldTimeouts(),
+		PartialData:             s.partialData,
+		ErrorPresenter:          s.errorPresenter,
 	}
 	varTypes := make(map[string]*introspection.Type)
 	for _, v := range op.Vars {
 		t, err := common.ResolveType(v.Type, s.schema.Resolve)
 		if err != nil {
-			return &Response{Errors: []*errors.QueryError{err}}
+			return &Response{Errors: []*errors.QueryError{err}}, nil
 		}
 		varTypes[v.Name.Name] = introspection.WrapType(t)
 	}
 	traceCtx, finish := s.tracer.TraceQuery(ctx, queryString, operationName, variables, varTypes)
+	if incremental {
+		data, errs, subsequent := r.ExecuteIncremental(traceCtx, res, op)
+		finish(errs)
+		resp := &Response{Data: data, Errors: errs, Extensions: collector.merge(extensions)}
+		s.afterExecution(ctx, doc, op, variables, resp)
+		return resp, subsequent
+	}
 	data, errs := r.Execute(traceCtx, res, op)
 	finish(errs)
 
-	return &Response{
-		Data:   data,
-		Errors: errs,
+	resp := &Response{
+		Data:       data,
+		Errors:     errs,
+		Extensions: collector.merge(extensions),
 	}
+	s.afterExecution(ctx, doc, op, variables, resp)
+	return resp, nil
 }
 
 func (s *Schema) validateSchema() error {
@@ -498,6 +765,13 @@ func (s *Schema) validateSchema() error {
 	if err := validateRootOp(s.schema, "subscription", false); err != nil {
 		return err
 	}
+	if !s.incrementalDelivery {
+		for _, name := range []string{"defer", "stream"} {
+			if _, ok := s.schema.Directives[name]; ok {
+				return fmt.Errorf("directive %q is reserved for incremental delivery, use the IncrementalDelivery option instead of defining it", name)
+			}
+		}
+	}
 	return nil
 }
 
@@ -743,6 +743,7 @@ func TestNilInterface(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       "x",
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 6}},
 					Path:          []any{"b"},
 					ResolverError: errors.New("x"),
 				},
@@ -751,6 +752,90 @@ func TestNilInterface(t *testing.T) {
 	})
 }
 
+type errorLocationsResolver struct{}
+
+func (r *errorLocationsResolver) Failing() (*string, error) {
+	return nil, errors.New("failed")
+}
+
+func (r *errorLocationsResolver) Panicking() *string {
+	panic("boom")
+}
+
+func (r *errorLocationsResolver) Items() *[]*errorLocationsItem {
+	return &[]*errorLocationsItem{{}, nil}
+}
+
+type errorLocationsItem struct{}
+
+func (*errorLocationsItem) Name() string { return "item" }
+
+func TestErrorLocations(t *testing.T) {
+	t.Parallel()
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: graphql.MustParseSchema(`
+				type Query {
+					failing: String
+					panicking: String
+					items: [Item!]
+				}
+
+				type Item {
+					name: String!
+				}
+			`, &errorLocationsResolver{}),
+			Query: `
+				{
+					failing
+					...F
+					...F
+					other: failing
+					panicking
+					items { name }
+				}
+
+				fragment F on Query {
+					failing
+				}
+			`,
+			ExpectedResult: `
+				{
+					"failing": null,
+					"other": null,
+					"panicking": null,
+					"items": null
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:       "failed",
+					Locations:     []gqlerrors.Location{{Line: 3, Column: 6}, {Line: 12, Column: 6}},
+					Path:          []any{"failing"},
+					ResolverError: errors.New("failed"),
+				},
+				{
+					Message:       "failed",
+					Locations:     []gqlerrors.Location{{Line: 6, Column: 6}},
+					Path:          []any{"other"},
+					ResolverError: errors.New("failed"),
+				},
+				{
+					Message:   "panic occurred: boom",
+					Locations: []gqlerrors.Location{{Line: 7, Column: 6}},
+					Path:      []any{"panicking"},
+				},
+				{
+					Message:   `graphql: got nil for non-null "Item"`,
+					Locations: []gqlerrors.Location{{Line: 8, Column: 6}},
+					Path:      []any{"items", 1},
+				},
+			},
+		},
+	})
+}
+
 func TestErrorPropagationInLists(t *testing.T) {
 	t.Parallel()
 
@@ -781,6 +866,7 @@ func TestErrorPropagationInLists(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       droidNotFoundError.Error(),
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 7}},
 					Path:          []any{"findDroids", 1, "name"},
 					ResolverError: droidNotFoundError,
 					Extensions:    map[string]any{"code": droidNotFoundError.Code, "message": droidNotFoundError.Message},
@@ -823,6 +909,7 @@ func TestErrorPropagationInLists(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       droidNotFoundError.Error(),
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 7}},
 					Path:          []any{"findDroids", 1, "name"},
 					ResolverError: droidNotFoundError,
 					Extensions:    map[string]any{"code": droidNotFoundError.Code, "message": droidNotFoundError.Message},
@@ -856,8 +943,9 @@ func TestErrorPropagationInLists(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: `graphql: got nil for non-null "Droid"`,
-					Path:    []any{"findNilDroids", 1},
+					Message:   `graphql: got nil for non-null "Droid"`,
+					Locations: []gqlerrors.Location{{Line: 3, Column: 6}},
+					Path:      []any{"findNilDroids", 1},
 				},
 			},
 		},
@@ -934,6 +1022,7 @@ func TestErrorPropagationInLists(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       errQuote.Error(),
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 7}},
 					ResolverError: errQuote,
 					Path:          []any{"findDroids", 0, "quotes"},
 				},
@@ -969,12 +1058,14 @@ func TestErrorPropagationInLists(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       errQuote.Error(),
+					Locations:     []gqlerrors.Location{{Line: 5, Column: 7}},
 					ResolverError: errQuote,
 					Path:          []any{"findNilDroids", 0, "quotes"},
 				},
 				{
-					Message: `graphql: got nil for non-null "Droid"`,
-					Path:    []any{"findNilDroids", 1},
+					Message:   `graphql: got nil for non-null "Droid"`,
+					Locations: []gqlerrors.Location{{Line: 3, Column: 6}},
+					Path:      []any{"findNilDroids", 1},
 				},
 			},
 		},
@@ -1013,6 +1104,7 @@ func TestErrorWithExtensions(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       droidNotFoundError.Error(),
+					Locations:     []gqlerrors.Location{{Line: 3, Column: 6}},
 					Path:          []any{"FindDroid"},
 					ResolverError: droidNotFoundError,
 					Extensions:    map[string]any{"code": droidNotFoundError.Code, "message": droidNotFoundError.Message},
@@ -1049,6 +1141,7 @@ func TestErrorWithNoExtensions(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       err.Error(),
+					Locations:     []gqlerrors.Location{{Line: 3, Column: 6}},
 					Path:          []any{"DismissVader"},
 					ResolverError: err,
 					Extensions:    nil,
@@ -1759,8 +1852,9 @@ func TestEnums(t *testing.T) {
 			}`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: "Invalid value STAR_TREK.\nExpected type Episode, found STAR_TREK.",
-					Path:    []any{"hero", "appearsIn", 0},
+					Message:   "Invalid value STAR_TREK.\nExpected type Episode, found STAR_TREK.",
+					Locations: []gqlerrors.Location{{Line: 5, Column: 7}},
+					Path:      []any{"hero", "appearsIn", 0},
 				},
 			},
 		},
@@ -3538,6 +3632,7 @@ func TestErrorPropagation(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       errExample.Error(),
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 6}},
 					ResolverError: errExample,
 					Path:          []any{"triggerError"},
 				},
@@ -3577,6 +3672,7 @@ func TestErrorPropagation(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       errExample.Error(),
+					Locations:     []gqlerrors.Location{{Line: 6, Column: 7}},
 					ResolverError: errExample,
 					Path:          []any{"child", "triggerError"},
 				},
@@ -3620,6 +3716,7 @@ func TestErrorPropagation(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       errExample.Error(),
+					Locations:     []gqlerrors.Location{{Line: 8, Column: 8}},
 					ResolverError: errExample,
 					Path:          []any{"child", "child", "triggerError"},
 				},
@@ -3666,6 +3763,7 @@ func TestErrorPropagation(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       errExample.Error(),
+					Locations:     []gqlerrors.Location{{Line: 8, Column: 8}},
 					ResolverError: errExample,
 					Path:          []any{"child", "child", "triggerError"},
 				},
@@ -3702,8 +3800,9 @@ func TestErrorPropagation(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: nilChildErrorString,
-					Path:    []any{"child", "nilChild"},
+					Message:   nilChildErrorString,
+					Locations: []gqlerrors.Location{{Line: 5, Column: 7}},
+					Path:      []any{"child", "nilChild"},
 				},
 			},
 		},
@@ -3742,8 +3841,9 @@ func TestErrorPropagation(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: nilChildErrorString,
-					Path:    []any{"child", "nilChild"},
+					Message:   nilChildErrorString,
+					Locations: []gqlerrors.Location{{Line: 6, Column: 7}},
+					Path:      []any{"child", "nilChild"},
 				},
 			},
 		},
@@ -3786,11 +3886,13 @@ func TestErrorPropagation(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: nilChildErrorString,
-					Path:    []any{"child", "child", "child", "nilChild"},
+					Message:   nilChildErrorString,
+					Locations: []gqlerrors.Location{{Line: 7, Column: 9}},
+					Path:      []any{"child", "child", "child", "nilChild"},
 				},
 				{
 					Message:       errExample.Error(),
+					Locations:     []gqlerrors.Location{{Line: 5, Column: 8}},
 					ResolverError: errExample,
 					Path:          []any{"child", "child", "triggerError"},
 				},
@@ -3830,8 +3932,9 @@ func TestErrorPropagation(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: nilChildErrorString,
-					Path:    []any{"child", "child", "nilChild"},
+					Message:   nilChildErrorString,
+					Locations: []gqlerrors.Location{{Line: 5, Column: 8}},
+					Path:      []any{"child", "child", "nilChild"},
 				},
 			},
 		},
@@ -4257,8 +4360,9 @@ func TestPointerReturnForNonNull(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: `graphql: got nil for non-null "Hello"`,
-					Path:    []any{"pointerReturn", "value"},
+					Message:   `graphql: got nil for non-null "Hello"`,
+					Locations: []gqlerrors.Location{{Line: 4, Column: 7}},
+					Path:      []any{"pointerReturn", "value"},
 				},
 			},
 		},
@@ -5015,7 +5119,7 @@ func TestSeparateResolvers(t *testing.T) {
 				{
 					// null propagates all the way up because msg is non-null
 					Data:   json.RawMessage(`null`),
-					Errors: []*gqlerrors.QueryError{gqlerrors.Errorf("%s", errResolver)},
+					Errors: []*gqlerrors.QueryError{{Message: errResolver.Error(), Locations: []gqlerrors.Location{{Line: 4, Column: 7}}}},
 				},
 				{
 					Data: json.RawMessage(`
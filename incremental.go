@@ -0,0 +1,88 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// SubsequentResponse is a payload delivered after the initial [Response] of an incremental
+// execution. It carries the results of deferred fragments and streamed list items.
+type SubsequentResponse struct {
+	Incremental []*IncrementalResult `json:"incremental,omitempty"`
+	HasNext     bool                 `json:"hasNext"`
+}
+
+// IncrementalResult is the result of a fragment marked with @defer, in which case Data holds the
+// fields of the fragment, or of list items of a field marked with @stream, in which case Items
+// holds the items. Path is the path of the object or list item the result belongs to.
+type IncrementalResult struct {
+	Data   json.RawMessage      `json:"data,omitempty"`
+	Items  json.RawMessage      `json:"items,omitempty"`
+	Errors []*errors.QueryError `json:"errors,omitempty"`
+	Path   []any                `json:"path"`
+	Label  string               `json:"label,omitempty"`
+}
+
+// IncrementalDelivery adds the @defer and @stream directives to the schema. Fragments marked with
+// @defer and list items of fields marked with @stream are delivered incrementally by
+// [Schema.ExecIncremental], while the other execution methods resolve them inline. Without this
+// option, the directives are not part of the schema and documents using them are rejected. The
+// option only has an effect on [ParseSchema] and [MustParseSchema], since clones share the schema
+// definition.
+func IncrementalDelivery() SchemaOpt {
+	return func(s *Schema) {
+		s.incrementalDelivery = true
+	}
+}
+
+// ExecIncremental executes the given request like [Schema.ExecRequest], but delivers fragments
+// marked with @defer and list items of fields marked with @stream incrementally, which requires the
+// [IncrementalDelivery] option. The returned
+// response holds the initial payload. If anything was deferred, the subsequent payloads are sent
+// on the returned channel, which is closed after the payload with HasNext set to false, otherwise
+// the channel is nil. If the context gets cancelled, the channel is closed without further payloads.
+func (s *Schema) ExecIncremental(ctx context.Context, req *Request) (*Response, <-chan *SubsequentResponse) {
+	if !s.res.QueryResolver.IsValid() {
+		panic("schema created without resolver, can not exec")
+	}
+	queryString, doc, errs := s.resolveDocument(ctx, req)
+	if len(errs) != 0 {
+		return &Response{Errors: errs}, nil
+	}
+	op, err := getOperation(doc, req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*errors.QueryError{errors.Errorf("%s", err)}}, nil
+	}
+	ctx, cancel := s.withExecutionTimeout(ctx)
+	resp, results := s.execOperation(ctx, queryString, doc, op, req.Variables, s.res, true)
+	if results == nil {
+		cancel()
+		return resp, nil
+	}
+
+	c := make(chan *SubsequentResponse)
+	go func() {
+		defer cancel()
+		defer close(c)
+		for result := range results {
+			sr := &SubsequentResponse{HasNext: result.HasNext}
+			for _, inc := range result.Incremental {
+				sr.Incremental = append(sr.Incremental, &IncrementalResult{
+					Data:   inc.Data,
+					Items:  inc.Items,
+					Errors: inc.Errors,
+					Path:   inc.Path,
+					Label:  inc.Label,
+				})
+			}
+			select {
+			case c <- sr:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return resp, c
+}
@@ -0,0 +1,190 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+)
+
+func collectSubsequent(t *testing.T, c <-chan *graphql.SubsequentResponse) []string {
+	t.Helper()
+	var got []string
+	for resp := range c {
+		b, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(b))
+	}
+	return got
+}
+
+func TestExecIncremental(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.IncrementalDelivery())
+
+	for _, tc := range []struct {
+		name       string
+		query      string
+		initial    string
+		subsequent []string
+	}{
+		{
+			name:    "defer",
+			query:   `{ hero { id ... @defer(label: "details") { name } } }`,
+			initial: `{"data":{"hero":{"id":"2001"}}}`,
+			subsequent: []string{
+				`{"incremental":[{"data":{"name":"R2-D2"},"path":["hero"],"label":"details"}],"hasNext":false}`,
+			},
+		},
+		{
+			name:    "defer fragment spread",
+			query:   `{ hero { id ...Details @defer } } fragment Details on Character { name }`,
+			initial: `{"data":{"hero":{"id":"2001"}}}`,
+			subsequent: []string{
+				`{"incremental":[{"data":{"name":"R2-D2"},"path":["hero"]}],"hasNext":false}`,
+			},
+		},
+		{
+			name:    "defer disabled",
+			query:   `{ hero { id ... @defer(if: false) { name } } }`,
+			initial: `{"data":{"hero":{"id":"2001","name":"R2-D2"}}}`,
+		},
+		{
+			name:    "stream",
+			query:   `{ hero { friends @stream(initialCount: 1, label: "friends") { name } } }`,
+			initial: `{"data":{"hero":{"friends":[{"name":"Luke Skywalker"}]}}}`,
+			subsequent: []string{
+				`{"incremental":[{"items":[{"name":"Han Solo"}],"path":["hero","friends",1],"label":"friends"}],"hasNext":true}`,
+				`{"incremental":[{"items":[{"name":"Leia Organa"}],"path":["hero","friends",2],"label":"friends"}],"hasNext":false}`,
+			},
+		},
+		{
+			name:    "stream all items initially",
+			query:   `{ hero { friends @stream(initialCount: 5) { name } } }`,
+			initial: `{"data":{"hero":{"friends":[{"name":"Luke Skywalker"},{"name":"Han Solo"},{"name":"Leia Organa"}]}}}`,
+		},
+		{
+			name:    "no directives",
+			query:   `{ hero { name } }`,
+			initial: `{"data":{"hero":{"name":"R2-D2"}}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, c := schema.ExecIncremental(context.Background(), &graphql.Request{Query: tc.query})
+			if got := responseJSON(t, resp); got != tc.initial {
+				t.Errorf("wrong initial response\nwant: %s\ngot:  %s", tc.initial, got)
+			}
+			if tc.subsequent == nil {
+				if c != nil {
+					t.Fatal("want nil channel")
+				}
+				return
+			}
+			if c == nil {
+				t.Fatal("want subsequent responses")
+			}
+			got := collectSubsequent(t, c)
+			if len(got) != len(tc.subsequent) {
+				t.Fatalf("wrong subsequent responses\nwant: %v\ngot:  %v", tc.subsequent, got)
+			}
+			for i := range got {
+				if got[i] != tc.subsequent[i] {
+					t.Errorf("wrong subsequent response %d\nwant: %s\ngot:  %s", i, tc.subsequent[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+type incrementalResolver struct{}
+
+func (*incrementalResolver) Item() *incrementalItemResolver { return &incrementalItemResolver{} }
+
+type incrementalItemResolver struct{}
+
+func (*incrementalItemResolver) Name() string { return "item" }
+
+func (*incrementalItemResolver) Broken() (string, error) { return "", errors.New("broken") }
+
+func TestExecIncrementalNullPropagation(t *testing.T) {
+	schema := graphql.MustParseSchema(`
+		type Query {
+			item: Item
+		}
+		type Item {
+			name: String!
+			broken: String!
+		}
+	`, &incrementalResolver{}, graphql.IncrementalDelivery())
+
+	resp, c := schema.ExecIncremental(context.Background(), &graphql.Request{Query: `{ item { broken ... @defer { name } } }`})
+	want := `{"errors":[{"message":"broken","locations":[{"line":1,"column":10}],"path":["item","broken"]}],"data":{"item":null}}`
+	if got := responseJSON(t, resp); got != want {
+		t.Errorf("wrong initial response\nwant: %s\ngot:  %s", want, got)
+	}
+	if c != nil {
+		t.Errorf("deferred fragment of a null object was delivered: %v", collectSubsequent(t, c))
+	}
+}
+
+func TestExecDeferInline(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.IncrementalDelivery())
+	resp := schema.Exec(context.Background(), `{ hero { id ... @defer { name } friends @stream { name } } }`, "", nil)
+	want := `{"data":{"hero":{"id":"2001","name":"R2-D2","friends":[{"name":"Luke Skywalker"},{"name":"Han Solo"},{"name":"Leia Organa"}]}}}`
+	if got := responseJSON(t, resp); got != want {
+		t.Errorf("wrong response\nwant: %s\ngot:  %s", want, got)
+	}
+}
+
+func TestIncrementalDeliveryDisabled(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})
+
+	resp, c := schema.ExecIncremental(context.Background(), &graphql.Request{Query: `{ hero { id ... @defer { name } } }`})
+	if c != nil {
+		t.Errorf("deferred fragment was delivered: %v", collectSubsequent(t, c))
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != `Unknown directive "@defer".` {
+		t.Errorf("want unknown directive error, got %v", resp.Errors)
+	}
+
+	resp = schema.Exec(context.Background(), `{ __schema { directives { name } } }`, "", nil)
+	want := `{"data":{"__schema":{"directives":[{"name":"deprecated"},{"name":"include"},{"name":"oneOf"},{"name":"skip"},{"name":"specifiedBy"}]}}}`
+	if got := responseJSON(t, resp); got != want {
+		t.Errorf("wrong directives\nwant: %s\ngot:  %s", want, got)
+	}
+}
+
+func TestIncrementalDirectivesReserved(t *testing.T) {
+	const sdl = `
+		directive @defer(if: Boolean) on FRAGMENT_SPREAD
+		type Query {
+			hello: String
+		}
+	`
+	for _, tc := range []struct {
+		name string
+		opts []graphql.SchemaOpt
+		want string
+	}{
+		{
+			name: "disabled",
+			want: `directive "defer" is reserved for incremental delivery, use the IncrementalDelivery option instead of defining it`,
+		},
+		{
+			name: "enabled",
+			opts: []graphql.SchemaOpt{graphql.IncrementalDelivery()},
+			want: `graphql: built-in directive "defer" can not be redefined`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := graphql.ParseSchema(sdl, nil, tc.opts...)
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("want error %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -13,11 +13,16 @@ import (
 
 type syntaxError string
 
+// tokenLimitError is raised when a document has more tokens than allowed.
+type tokenLimitError int
+
 type Lexer struct {
 	sc                    *scanner.Scanner
 	next                  rune
 	comment               bytes.Buffer
 	useStringDescriptions bool
+	maxTokens             int
+	tokens                int
 }
 
 type Ident struct {
@@ -37,6 +42,12 @@ func NewLexer(s string, useStringDescriptions bool) *Lexer {
 	return &l
 }
 
+// SetMaxTokens stops the lexer with an error once more than n tokens are consumed. The default is
+// 0 which disables the limit.
+func (l *Lexer) SetMaxTokens(n int) {
+	l.maxTokens = n
+}
+
 func (l *Lexer) CatchSyntaxError(f func()) (errRes *errors.QueryError) {
 	defer func() {
 		if err := recover(); err != nil {
@@ -46,6 +57,13 @@ func (l *Lexer) CatchSyntaxError(f func()) (errRes *errors.QueryError) {
 				errRes.Locations = []errors.Location{l.Location()}
 				return
 			}
+			if n, ok := err.(tokenLimitError); ok {
+				errRes = errors.Errorf("Document exceeds the maximum of %d tokens.", int(n))
+				errRes.Locations = []errors.Location{l.Location()}
+				errRes.Rule = "MaxTokensExceeded"
+				errRes.Extensions = map[string]any{"code": "MAX_TOKENS_EXCEEDED"}
+				return
+			}
 			panic(err)
 		}
 	}()
@@ -89,6 +107,13 @@ func (l *Lexer) ConsumeWhitespace() {
 
 		break
 	}
+
+	if l.maxTokens > 0 && l.next != scanner.EOF {
+		l.tokens++
+		if l.tokens > l.maxTokens {
+			panic(tokenLimitError(l.maxTokens))
+		}
+	}
 }
 
 // consumeDescription optionally consumes a description based on the June 2018 graphql spec if any are present.
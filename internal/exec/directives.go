@@ -0,0 +1,19 @@
+package exec
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+)
+
+type directivesKey struct{}
+
+// DirectivesFromContext returns the directives applied in the query to the field being resolved.
+func DirectivesFromContext(ctx context.Context) []*resolvable.Directive {
+	ds, _ := ctx.Value(directivesKey{}).([]*resolvable.Directive)
+	return ds
+}
+
+func withDirectives(ctx context.Context, ds []*resolvable.Directive) context.Context {
+	return context.WithValue(ctx, directivesKey{}, ds)
+}
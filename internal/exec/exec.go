@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"slices"
 	"sync"
 	"time"
 
@@ -37,8 +38,28 @@ type Request struct {
 	DisableFieldSelections   bool
 	DisableMemoryPooling     bool
 	MaxPooledBufferCapacity  int
+	FieldMiddleware          FieldMiddleware
+	SkipTrivialMiddleware    bool
+	DirectiveHandler         DirectiveHandler
+	FieldTimeouts            map[FieldKey]time.Duration
+	PartialData              bool // return the data resolved before the context was cancelled
+	ErrorPresenter           ErrorPresenter
+
+	pending []*incrementalTask // guarded by Mu
 }
 
+// FieldMiddleware wraps the resolution of a field. The next function calls the resolver and
+// returns its result, after calling the thunk returned by the resolver, if any.
+type FieldMiddleware func(ctx context.Context, field *selected.SchemaField, path []any, next func(context.Context) (any, error)) (any, error)
+
+// DirectiveHandler calls the handler bound to a directive applied in the schema, which wraps the
+// resolution of a field like the field middleware.
+type DirectiveHandler func(ctx context.Context, d *resolvable.Directive, field *selected.SchemaField, path []any, next func(context.Context) (any, error)) (any, error)
+
+// ErrorPresenter converts an error returned by a resolver to the error added to the response. If
+// it returns nil, the error is converted as if there was no presenter.
+type ErrorPresenter func(ctx context.Context, err error) *errors.QueryError
+
 func (r *Request) handlePanic(ctx context.Context) {
 	if value := recover(); value != nil {
 		r.Logger.LogPanic(ctx, value)
@@ -50,7 +71,33 @@ type extensionser interface {
 	Extensions() map[string]any
 }
 
+// presentError returns the error presented by the error presenter for an error returned by the
+// resolver of f, or nil if there is no presenter or it returned nil. The presented error is
+// copied, so that presenters may return shared errors, and its path and locations default to the
+// ones of the field.
+func (r *Request) presentError(ctx context.Context, f *fieldToExec, path *pathSegment, resolverErr error) *errors.QueryError {
+	if r.ErrorPresenter == nil {
+		return nil
+	}
+	presented := r.ErrorPresenter(ctx, resolverErr)
+	if presented == nil {
+		return nil
+	}
+	err := *presented
+	if err.Path == nil {
+		err.Path = path.toSlice()
+	}
+	if err.Locations == nil {
+		err.Locations = f.locations()
+	}
+	if err.ResolverError == nil {
+		err.ResolverError = resolverErr
+	}
+	return &err
+}
+
 func (r *Request) Execute(ctx context.Context, s *resolvable.Schema, op *ast.OperationDefinition) ([]byte, []*errors.QueryError) {
+	ctx = withLoaders(ctx, r.Tracer)
 	var out bytes.Buffer
 	func() {
 		defer r.handlePanic(ctx)
@@ -76,7 +123,7 @@ func (r *Request) Execute(ctx context.Context, s *resolvable.Schema, op *ast.Ope
 		r.execSelections(ctx, sels, nil, s, resolver, &out, op.Type == query.Mutation)
 	}()
 
-	if err := ctx.Err(); err != nil {
+	if err := ctx.Err(); err != nil && !r.PartialData {
 		return nil, []*errors.QueryError{errors.Errorf("%s", err)}
 	}
 
@@ -93,12 +140,68 @@ type fieldToExec struct {
 	sels     []selected.Selection
 	resolver reflect.Value
 	out      *bytes.Buffer
+
+	// The locations of all selections of the field, if it is selected more than once.
+	locs []errors.Location
+
+	// The result of a resolver which was called before the field is executed, see preload.
+	preloaded  bool
+	result     reflect.Value
+	err        error
+	panicValue any
+}
+
+// locations returns the locations of the field in the query.
+func (f *fieldToExec) locations() []errors.Location {
+	if f.locs != nil {
+		return f.locs
+	}
+	return f.field.Locations
+}
+
+// addLocations adds the locations of another selection of the field, which is merged into f.
+func (f *fieldToExec) addLocations(locs []errors.Location) {
+	for _, loc := range locs {
+		if slices.Contains(f.locations(), loc) {
+			continue
+		}
+		f.locs = append(slices.Clip(f.locations()), loc)
+	}
+}
+
+// objectToExec holds the fields to resolve for an object.
+type objectToExec struct {
+	sels     []selected.Selection
+	resolver reflect.Value
+	fields   []*fieldToExec
+	deferred []*deferredFragment
+}
+
+type deferredFragment struct {
+	sels     []selected.Selection
+	label    string
+	resolver reflect.Value
 }
 
 func (f *fieldToExec) resolve(ctx context.Context) (reflect.Value, error) {
+	if f.preloaded {
+		if f.panicValue != nil {
+			panic(f.panicValue)
+		}
+		return f.result, f.err
+	}
 	return f.field.Resolve(ctx, f.resolver)
 }
 
+// resolveValue resolves the field and calls the returned thunk, if any.
+func (f *fieldToExec) resolveValue(ctx context.Context) (reflect.Value, error) {
+	result, err := f.resolve(ctx)
+	if err == nil && result.Kind() == reflect.Func {
+		return callThunk(result)
+	}
+	return result, err
+}
+
 func resolvedToNull(b *bytes.Buffer) bool {
 	return bytes.Equal(b.Bytes(), nullLiteral)
 }
@@ -133,10 +236,22 @@ func (r *Request) releaseFieldBuffers(fields []*fieldToExec) {
 }
 
 func (r *Request) execSelections(ctx context.Context, sels []selected.Selection, path *pathSegment, s *resolvable.Schema, resolver reflect.Value, out *bytes.Buffer, serially bool) {
-	async := !serially && selected.HasAsyncSel(sels)
+	o := collectObject(sels, s, resolver)
+	if !serially {
+		r.preload(ctx, o.fields, path)
+	}
+	r.execObject(ctx, o, path, s, out, serially)
+}
+
+func collectObject(sels []selected.Selection, s *resolvable.Schema, resolver reflect.Value) *objectToExec {
+	o := &objectToExec{sels: sels, resolver: resolver}
+	collectFieldsToResolve(sels, s, resolver, &o.fields, &o.deferred, make(map[string]*fieldToExec))
+	return o
+}
 
-	var fields []*fieldToExec
-	collectFieldsToResolve(sels, s, resolver, &fields, make(map[string]*fieldToExec))
+func (r *Request) execObject(ctx context.Context, o *objectToExec, path *pathSegment, s *resolvable.Schema, out *bytes.Buffer, serially bool) {
+	async := !serially && selected.HasAsyncSel(o.sels)
+	fields, deferred := o.fields, o.deferred
 
 	if async {
 		var wg sync.WaitGroup
@@ -146,14 +261,14 @@ func (r *Request) execSelections(ctx context.Context, sels []selected.Selection,
 				defer wg.Done()
 				defer r.handlePanic(ctx)
 				f.out = r.acquireBuffer()
-				execFieldSelection(ctx, r, s, f, &pathSegment{path, f.field.Alias}, true)
+				execFieldSelection(ctx, r, s, f, fieldPath(path, f), true)
 			}(f)
 		}
 		wg.Wait()
 	} else {
 		for _, f := range fields {
 			f.out = r.acquireBuffer()
-			execFieldSelection(ctx, r, s, f, &pathSegment{path, f.field.Alias}, true)
+			execFieldSelection(ctx, r, s, f, fieldPath(path, f), true)
 		}
 	}
 
@@ -164,6 +279,7 @@ func (r *Request) execSelections(ctx context.Context, sels []selected.Selection,
 		// If this field is non-nullable, the error is propagated to its parent.
 		if _, ok := f.field.Type.(*ast.NonNull); ok && resolvedToNull(f.out) {
 			r.releaseFieldBuffers(fields)
+			r.dropPending(path)
 			out.Reset()
 			out.Write(nullLiteral)
 			return
@@ -181,9 +297,13 @@ func (r *Request) execSelections(ctx context.Context, sels []selected.Selection,
 		f.out = nil
 	}
 	out.WriteByte('}')
+
+	for _, d := range deferred {
+		r.deferFragment(s, d, path)
+	}
 }
 
-func collectFieldsToResolve(sels []selected.Selection, s *resolvable.Schema, resolver reflect.Value, fields *[]*fieldToExec, fieldByAlias map[string]*fieldToExec) {
+func collectFieldsToResolve(sels []selected.Selection, s *resolvable.Schema, resolver reflect.Value, fields *[]*fieldToExec, deferred *[]*deferredFragment, fieldByAlias map[string]*fieldToExec) {
 	for _, sel := range sels {
 		switch sel := sel.(type) {
 		case *selected.SchemaField:
@@ -192,6 +312,8 @@ func collectFieldsToResolve(sels []selected.Selection, s *resolvable.Schema, res
 				field = &fieldToExec{field: sel, resolver: resolver}
 				fieldByAlias[sel.Alias] = field
 				*fields = append(*fields, field)
+			} else {
+				field.addLocations(sel.Locations)
 			}
 			field.sels = append(field.sels, sel.Sels...)
 
@@ -218,7 +340,10 @@ func collectFieldsToResolve(sels []selected.Selection, s *resolvable.Schema, res
 			if !out[1].Bool() {
 				continue
 			}
-			collectFieldsToResolve(sel.Sels, s, out[0], fields, fieldByAlias)
+			collectFieldsToResolve(sel.Sels, s, out[0], fields, deferred, fieldByAlias)
+
+		case *selected.DeferredFragment:
+			*deferred = append(*deferred, &deferredFragment{sels: sel.Sels, label: sel.Label, resolver: resolver})
 
 		default:
 			panic("unreachable")
@@ -256,6 +381,7 @@ func execFieldSelection(ctx context.Context, r *Request, s *resolvable.Schema, f
 				r.Logger.LogPanic(ctx, panicValue)
 				err = r.PanicHandler.MakePanicError(ctx, panicValue)
 				err.Path = path.toSlice()
+				err.Locations = f.locations()
 			}
 		}()
 
@@ -265,17 +391,29 @@ func execFieldSelection(ctx context.Context, r *Request, s *resolvable.Schema, f
 		}
 
 		if err := traceCtx.Err(); err != nil {
-			return errors.Errorf("%s", err) // don't execute any more resolvers if context got cancelled
+			// don't execute any more resolvers if context got cancelled
+			err := errors.Errorf("%s", err)
+			err.Path = path.toSlice()
+			err.Locations = f.locations()
+			return err
 		}
 
 		if len(f.sels) > 0 && !r.DisableFieldSelections {
 			ctx = selections.With(traceCtx, f.sels)
 		}
 		var resolverErr error
-		result, resolverErr = f.resolve(ctx)
+		if timeout := r.fieldTimeout(f.field); timeout > 0 {
+			result, resolverErr = r.resolveFieldWithTimeout(ctx, f, path, timeout)
+		} else {
+			result, resolverErr = r.resolveField(ctx, f, path)
+		}
 		if resolverErr != nil {
+			if err := r.presentError(ctx, f, path, resolverErr); err != nil {
+				return err
+			}
 			err := errors.Errorf("%s", resolverErr)
 			err.Path = path.toSlice()
+			err.Locations = f.locations()
 			err.ResolverError = resolverErr
 			if ex, ok := resolverErr.(extensionser); ok {
 				err.Extensions = ex.Extensions()
@@ -298,9 +436,79 @@ func execFieldSelection(ctx context.Context, r *Request, s *resolvable.Schema, f
 		return
 	}
 
+	if f.field.Stream != nil {
+		r.execStream(traceCtx, f, path, s, result)
+		return
+	}
 	r.execSelectionSet(traceCtx, f.sels, f.field.Type, path, s, result, f.out)
 }
 
+// resolveField calls the resolver of the field through the field middleware and the handlers of
+// the directives applied to the field in the schema and in the query.
+func (r *Request) resolveField(ctx context.Context, f *fieldToExec, path *pathSegment) (reflect.Value, error) {
+	ctx = r.withField(ctx, f.field, path)
+	if len(f.field.QueryDirectives) > 0 {
+		ctx = withDirectives(ctx, f.field.QueryDirectives)
+	}
+	useMiddleware := r.useMiddleware(f.field)
+	directives := r.fieldDirectives(f.field)
+	if !useMiddleware && len(directives) == 0 {
+		return f.resolveValue(ctx)
+	}
+
+	p := path.toSlice()
+	next := func(ctx context.Context) (any, error) {
+		result, err := f.resolveValue(ctx)
+		if !result.IsValid() {
+			return nil, err
+		}
+		return result.Interface(), err
+	}
+	for i := len(directives) - 1; i >= 0; i-- {
+		d, inner := directives[i], next
+		next = func(ctx context.Context) (any, error) {
+			return r.DirectiveHandler(ctx, d, f.field, p, inner)
+		}
+	}
+	var res any
+	var err error
+	if useMiddleware {
+		res, err = r.FieldMiddleware(ctx, f.field, p, next)
+	} else {
+		res, err = next(ctx)
+	}
+	if res == nil {
+		return reflect.Value{}, err
+	}
+	v := reflect.ValueOf(res)
+	if !v.Type().AssignableTo(f.field.OutputType) {
+		return reflect.Value{}, fmt.Errorf("graphql: field middleware returned %T for field %s.%s, expected %s", res, f.field.TypeName, f.field.Name, f.field.OutputType)
+	}
+	// Keep the declared type of the resolver, since method indices refer to its method set.
+	result := reflect.New(f.field.OutputType).Elem()
+	result.Set(v)
+	return result, err
+}
+
+// useMiddleware reports whether the field middleware is invoked for f.
+func (r *Request) useMiddleware(f *selected.SchemaField) bool {
+	return r.FieldMiddleware != nil && !(r.SkipTrivialMiddleware && !f.UseMethodResolver())
+}
+
+// fieldDirectives returns the directives whose handlers wrap the resolution of f.
+func (r *Request) fieldDirectives(f *selected.SchemaField) []*resolvable.Directive {
+	if r.DirectiveHandler == nil {
+		return nil
+	}
+	directives := f.Directives
+	for _, d := range f.QueryDirectives {
+		if d.Handler != nil {
+			directives = append(directives[:len(directives):len(directives)], d)
+		}
+	}
+	return directives
+}
+
 func (r *Request) execSelectionSet(ctx context.Context, sels []selected.Selection, typ ast.Type, path *pathSegment, s *resolvable.Schema, resolver reflect.Value, out *bytes.Buffer) {
 	t, nonNull := unwrapNonNull(typ)
 
@@ -312,6 +520,7 @@ func (r *Request) execSelectionSet(ctx context.Context, sels []selected.Selectio
 		if nonNull {
 			err := errors.Errorf("graphql: got nil for non-null %q", t)
 			err.Path = path.toSlice()
+			err.Locations = path.fieldLocations()
 			r.AddError(err)
 		}
 		out.WriteString("null")
@@ -358,6 +567,7 @@ func (r *Request) execSelectionSet(ctx context.Context, sels []selected.Selectio
 		if !valid {
 			err := errors.Errorf("Invalid value %s.\nExpected type %s, found %s.", name, t.Name, name)
 			err.Path = path.toSlice()
+			err.Locations = path.fieldLocations()
 			r.AddError(err)
 			out.WriteString("null")
 			return
@@ -374,6 +584,14 @@ func (r *Request) execSelectionSet(ctx context.Context, sels []selected.Selectio
 func (r *Request) execList(ctx context.Context, sels []selected.Selection, typ *ast.List, path *pathSegment, s *resolvable.Schema, resolver reflect.Value, out *bytes.Buffer) {
 	l := resolver.Len()
 	entryouts := make([]*bytes.Buffer, l)
+	objs := r.collectListObjects(ctx, sels, typ, path, s, resolver)
+	execItem := func(i int) {
+		if objs != nil && objs[i] != nil {
+			r.execObject(ctx, objs[i], &pathSegment{parent: path, value: i}, s, entryouts[i], false)
+			return
+		}
+		r.execSelectionSet(ctx, sels, typ.OfType, &pathSegment{parent: path, value: i}, s, resolver.Index(i), entryouts[i])
+	}
 
 	if selected.HasAsyncSel(sels) {
 		// Limit the number of concurrent goroutines spawned as it can lead to large
@@ -392,14 +610,14 @@ func (r *Request) execList(ctx context.Context, sels []selected.Selection, typ *
 				defer wg.Done()
 				defer func() { <-sem }()
 				defer r.handlePanic(ctx)
-				r.execSelectionSet(ctx, sels, typ.OfType, &pathSegment{path, i}, s, resolver.Index(i), entryouts[i])
+				execItem(i)
 			}(i)
 		}
 		wg.Wait()
 	} else {
 		for i := range l {
 			entryouts[i] = r.acquireBuffer()
-			r.execSelectionSet(ctx, sels, typ.OfType, &pathSegment{path, i}, s, resolver.Index(i), entryouts[i])
+			execItem(i)
 		}
 	}
 
@@ -414,6 +632,7 @@ func (r *Request) execList(ctx context.Context, sels []selected.Selection, typ *
 				r.releaseBuffer(b)
 				entryouts[j] = nil
 			}
+			r.dropPending(path)
 			out.Reset()
 			out.Write(nullLiteral)
 			return
@@ -429,6 +648,32 @@ func (r *Request) execList(ctx context.Context, sels []selected.Selection, typ *
 	out.WriteByte(']')
 }
 
+// collectListObjects collects the fields of the objects in a list whose selections contain fields
+// returning thunks and calls their resolvers, so that the keys loaded for all items are loaded in
+// one batch. It returns nil if the list does not contain objects with such fields.
+func (r *Request) collectListObjects(ctx context.Context, sels []selected.Selection, typ *ast.List, path *pathSegment, s *resolvable.Schema, resolver reflect.Value) []*objectToExec {
+	t, _ := unwrapNonNull(typ.OfType)
+	switch t.(type) {
+	case *ast.ObjectTypeDefinition, *ast.InterfaceTypeDefinition, *ast.Union:
+	default:
+		return nil
+	}
+	if !hasThunkSel(sels) {
+		return nil
+	}
+
+	objs := make([]*objectToExec, resolver.Len())
+	for i := range objs {
+		item := resolver.Index(i)
+		if item.Kind() == reflect.Invalid || ((item.Kind() == reflect.Pointer || item.Kind() == reflect.Interface) && item.IsNil()) {
+			continue
+		}
+		objs[i] = collectObject(sels, s, item)
+		r.preload(ctx, objs[i].fields, &pathSegment{parent: path, value: i})
+	}
+	return objs
+}
+
 func unwrapNonNull(t ast.Type) (ast.Type, bool) {
 	if nn, ok := t.(*ast.NonNull); ok {
 		return nn.OfType, true
@@ -437,8 +682,24 @@ func unwrapNonNull(t ast.Type) (ast.Type, bool) {
 }
 
 type pathSegment struct {
-	parent *pathSegment
-	value  any
+	parent    *pathSegment
+	value     any
+	locations []errors.Location // locations of the field in the query, nil for list items
+}
+
+// fieldPath returns the path of a field of the object at path.
+func fieldPath(path *pathSegment, f *fieldToExec) *pathSegment {
+	return &pathSegment{parent: path, value: f.field.Alias, locations: f.locations()}
+}
+
+// fieldLocations returns the locations of the innermost field of the path.
+func (p *pathSegment) fieldLocations() []errors.Location {
+	for ; p != nil; p = p.parent {
+		if p.locations != nil {
+			return p.locations
+		}
+	}
+	return nil
 }
 
 func (p *pathSegment) toSlice() []any {
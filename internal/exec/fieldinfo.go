@@ -0,0 +1,32 @@
+package exec
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go/internal/exec/selected"
+)
+
+type resolvingFieldKey struct{}
+
+type resolvingField struct {
+	field *selected.SchemaField
+	path  *pathSegment
+}
+
+// FieldFromContext returns the field being resolved and its path in the response.
+func FieldFromContext(ctx context.Context) (*selected.SchemaField, []any, bool) {
+	f, ok := ctx.Value(resolvingFieldKey{}).(*resolvingField)
+	if !ok {
+		return nil, nil, false
+	}
+	return f.field, f.path.toSlice(), true
+}
+
+// withField returns a context for resolving the field, unless the resolver, the field middleware
+// and the directive handlers can not read the context.
+func (r *Request) withField(ctx context.Context, f *selected.SchemaField, path *pathSegment) context.Context {
+	if !f.HasContext && r.FieldMiddleware == nil && r.DirectiveHandler == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, resolvingFieldKey{}, &resolvingField{field: f, path: path})
+}
@@ -0,0 +1,231 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+	"github.com/graph-gophers/graphql-go/internal/exec/selected"
+)
+
+// IncrementalResult is the result of a deferred fragment or of streamed list items.
+type IncrementalResult struct {
+	Data   []byte // result of a deferred fragment
+	Items  []byte // JSON array of streamed list items
+	Errors []*errors.QueryError
+	Path   []any
+	Label  string
+}
+
+// SubsequentResult is delivered after the initial result of an incremental execution.
+type SubsequentResult struct {
+	Incremental []*IncrementalResult
+	HasNext     bool
+}
+
+// incrementalTask executes a deferred fragment or a streamed list item after the result that
+// contains it has been delivered. Tasks are dropped if the value at their path resolves to null.
+type incrementalTask struct {
+	path []any
+	run  func(ctx context.Context, r *Request) *IncrementalResult
+}
+
+// ExecuteIncremental executes the operation like Execute, but delivers the results of deferred
+// fragments and streamed list items on the returned channel. The channel is nil if nothing was
+// deferred, otherwise it is closed after the result with HasNext set to false.
+func (r *Request) ExecuteIncremental(ctx context.Context, s *resolvable.Schema, op *ast.OperationDefinition) ([]byte, []*errors.QueryError, <-chan *SubsequentResult) {
+	r.Incremental = true
+	// Deferred fragments share the loaders of the initial result.
+	ctx = withLoaders(ctx, r.Tracer)
+	data, errs := r.Execute(ctx, s, op)
+	tasks := r.takePending()
+	if data == nil || len(tasks) == 0 || ctx.Err() != nil {
+		return data, errs, nil
+	}
+
+	sc := &scheduler{ctx: ctx, out: make(chan *SubsequentResult), pending: len(tasks)}
+	for _, t := range tasks {
+		go sc.run(r, t)
+	}
+	return data, errs, sc.out
+}
+
+type scheduler struct {
+	ctx     context.Context
+	out     chan *SubsequentResult
+	mu      sync.Mutex
+	pending int
+}
+
+func (sc *scheduler) run(parent *Request, t *incrementalTask) {
+	r := parent.fork()
+	res := t.run(sc.ctx, r)
+	children := r.takePending()
+	if sc.ctx.Err() != nil {
+		children = nil
+	}
+
+	sc.mu.Lock()
+	sc.pending += len(children) - 1
+	hasNext := sc.pending > 0
+	if res != nil || !hasNext {
+		result := &SubsequentResult{HasNext: hasNext}
+		if res != nil {
+			result.Incremental = []*IncrementalResult{res}
+		}
+		select {
+		case sc.out <- result:
+		case <-sc.ctx.Done():
+		}
+	}
+	if !hasNext {
+		close(sc.out)
+	}
+	sc.mu.Unlock()
+
+	for _, child := range children {
+		go sc.run(r, child)
+	}
+}
+
+// fork returns a request for executing an incremental task, which collects its own errors.
+func (r *Request) fork() *Request {
+	return &Request{
+		Request: selected.Request{
+			Schema:             r.Schema,
+			Doc:                r.Doc,
+			Vars:               r.Vars,
+			AllowIntrospection: r.AllowIntrospection,
+			Incremental:        r.Incremental,
+		},
+		Limiter:                 r.Limiter,
+		Tracer:                  r.Tracer,
+		Logger:                  r.Logger,
+		PanicHandler:            r.PanicHandler,
+		DisableFieldSelections:  r.DisableFieldSelections,
+		DisableMemoryPooling:    r.DisableMemoryPooling,
+		MaxPooledBufferCapacity: r.MaxPooledBufferCapacity,
+		FieldMiddleware:         r.FieldMiddleware,
+		SkipTrivialMiddleware:   r.SkipTrivialMiddleware,
+		DirectiveHandler:        r.DirectiveHandler,
+		FieldTimeouts:           r.FieldTimeouts,
+		ErrorPresenter:          r.ErrorPresenter,
+		PartialData:             r.PartialData,
+	}
+}
+
+func (r *Request) addPending(t *incrementalTask) {
+	r.Mu.Lock()
+	r.pending = append(r.pending, t)
+	r.Mu.Unlock()
+}
+
+func (r *Request) takePending() []*incrementalTask {
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+	tasks := r.pending
+	r.pending = nil
+	return tasks
+}
+
+// dropPending removes the tasks below a path whose value resolved to null.
+func (r *Request) dropPending(path *pathSegment) {
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+	if len(r.pending) == 0 {
+		return
+	}
+	prefix := path.toSlice()
+	kept := r.pending[:0]
+	for _, t := range r.pending {
+		if !hasPathPrefix(t.path, prefix) {
+			kept = append(kept, t)
+		}
+	}
+	clear(r.pending[len(kept):])
+	r.pending = kept
+}
+
+func hasPathPrefix(path, prefix []any) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// deferFragment registers a deferred fragment of the object at path.
+func (r *Request) deferFragment(s *resolvable.Schema, d *deferredFragment, path *pathSegment) {
+	p := path.toSlice()
+	if p == nil {
+		p = []any{}
+	}
+	r.addPending(&incrementalTask{path: p, run: func(ctx context.Context, r *Request) *IncrementalResult {
+		var out bytes.Buffer
+		func() {
+			defer r.handlePanic(ctx)
+			r.execSelections(ctx, d.sels, path, s, d.resolver, &out, false)
+		}()
+		if out.Len() == 0 {
+			out.Write(nullLiteral)
+		}
+		return &IncrementalResult{Data: out.Bytes(), Errors: r.Errs, Path: p, Label: d.label}
+	}})
+}
+
+// execStream executes the initial items of a list field marked with @stream and registers the
+// remaining items for incremental delivery.
+func (r *Request) execStream(ctx context.Context, f *fieldToExec, path *pathSegment, s *resolvable.Schema, resolver reflect.Value) {
+	t, _ := unwrapNonNull(f.field.Type)
+	list, ok := t.(*ast.List)
+	if resolver.Kind() == reflect.Pointer || resolver.Kind() == reflect.Interface {
+		if resolver.IsNil() {
+			ok = false
+		} else {
+			resolver = resolver.Elem()
+		}
+	}
+	if !ok || resolver.Kind() != reflect.Slice {
+		r.execSelectionSet(ctx, f.sels, f.field.Type, path, s, resolver, f.out)
+		return
+	}
+
+	initial := min(f.field.Stream.InitialCount, resolver.Len())
+	r.execList(ctx, f.sels, list, path, s, resolver.Slice(0, initial), f.out)
+	if initial < resolver.Len() && !resolvedToNull(f.out) {
+		r.addPending(r.streamItem(s, f.sels, list, path, resolver, initial, f.field.Stream.Label))
+	}
+}
+
+// streamItem returns the task delivering the list item with index i. Each item registers the
+// task of the next item, so that items are delivered in order.
+func (r *Request) streamItem(s *resolvable.Schema, sels []selected.Selection, list *ast.List, path *pathSegment, resolver reflect.Value, i int, label string) *incrementalTask {
+	itemPath := &pathSegment{parent: path, value: i}
+	p := itemPath.toSlice()
+	return &incrementalTask{path: p, run: func(ctx context.Context, r *Request) *IncrementalResult {
+		var out bytes.Buffer
+		func() {
+			defer r.handlePanic(ctx)
+			r.execSelectionSet(ctx, sels, list.OfType, itemPath, s, resolver.Index(i), &out)
+		}()
+		res := &IncrementalResult{Errors: r.Errs, Path: p, Label: label}
+		if _, nonNull := list.OfType.(*ast.NonNull); out.Len() == 0 || (nonNull && resolvedToNull(&out)) {
+			// A null item of a list of non-null items ends the stream.
+			res.Items = nullLiteral
+			return res
+		}
+		res.Items = append(append([]byte{'['}, out.Bytes()...), ']')
+		if i+1 < resolver.Len() {
+			r.addPending(r.streamItem(s, sels, list, path, resolver, i+1, label))
+		}
+		return res
+	}}
+}
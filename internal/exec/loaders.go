@@ -0,0 +1,118 @@
+package exec
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go/internal/exec/selected"
+	"github.com/graph-gophers/graphql-go/internal/exec/selections"
+	"github.com/graph-gophers/graphql-go/trace/tracer"
+)
+
+// Loaders holds the state of the batch loaders used during the execution of a request, so that
+// loaded values are cached per request.
+type Loaders struct {
+	tracer tracer.Tracer
+	mu     sync.Mutex
+	states map[any]any
+}
+
+type loadersKey struct{}
+
+// LoadersFromContext returns the loaders of the request executing with ctx, or nil if ctx does
+// not belong to a request.
+func LoadersFromContext(ctx context.Context) *Loaders {
+	l, _ := ctx.Value(loadersKey{}).(*Loaders)
+	return l
+}
+
+// withLoaders returns a context with new loaders unless ctx already has loaders.
+func withLoaders(ctx context.Context, t tracer.Tracer) context.Context {
+	if LoadersFromContext(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, loadersKey{}, &Loaders{tracer: t, states: make(map[any]any)})
+}
+
+// State returns the state of the loader identified by key, creating it on first use.
+func (l *Loaders) State(key any, create func() any) any {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	st, ok := l.states[key]
+	if !ok {
+		st = create()
+		l.states[key] = st
+	}
+	return st
+}
+
+// TraceBatch starts tracing a batch of keys loaded by the named loader, if the tracer supports it.
+func (l *Loaders) TraceBatch(ctx context.Context, name string, keys int) (context.Context, tracer.BatchFinishFunc) {
+	if bt, ok := l.tracer.(tracer.BatchTracer); ok {
+		return bt.TraceBatch(ctx, name, keys)
+	}
+	return ctx, func(error) {}
+}
+
+// preload calls the resolvers of the fields which return thunks, so that the keys loaded by all
+// siblings are collected before the first thunk is called and can be loaded in one batch. Fields
+// whose resolution is wrapped by field middleware, directive handlers or a timeout are not
+// preloaded, since the wrappers may decide not to call the resolver.
+func (r *Request) preload(ctx context.Context, fields []*fieldToExec, path *pathSegment) {
+	for _, f := range fields {
+		if !f.field.Thunk || f.field.FixedResult.IsValid() || f.preloaded {
+			continue
+		}
+		if r.useMiddleware(f.field) || len(r.fieldDirectives(f.field)) > 0 || r.fieldTimeout(f.field) > 0 {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		f.preloaded = true
+		func() {
+			defer func() {
+				// The panic is handled once the field is executed.
+				f.panicValue = recover()
+			}()
+			fctx := r.withField(ctx, f.field, fieldPath(path, f))
+			if len(f.sels) > 0 && !r.DisableFieldSelections {
+				fctx = selections.With(fctx, f.sels)
+			}
+			if len(f.field.QueryDirectives) > 0 {
+				fctx = withDirectives(fctx, f.field.QueryDirectives)
+			}
+			f.result, f.err = f.field.Resolve(fctx, f.resolver)
+		}()
+	}
+}
+
+// hasThunkSel reports whether any field of the selections returns a thunk.
+func hasThunkSel(sels []selected.Selection) bool {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *selected.SchemaField:
+			if sel.Thunk {
+				return true
+			}
+		case *selected.TypeAssertion:
+			if hasThunkSel(sel.Sels) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// callThunk returns the value of a thunk returned by a resolver.
+func callThunk(thunk reflect.Value) (reflect.Value, error) {
+	if thunk.IsNil() {
+		return reflect.Value{}, nil
+	}
+	out := thunk.Call(nil)
+	if err, _ := out[1].Interface().(error); err != nil {
+		return out[0], err
+	}
+	return out[0], nil
+}
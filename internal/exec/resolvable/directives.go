@@ -0,0 +1,158 @@
+package resolvable
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/ast"
+)
+
+// Directive is a directive applied in the schema to a field, to an argument of a field or to the
+// object type of a field, or a directive applied in a query, which is bound to the handler
+// registered for its name.
+type Directive struct {
+	Name     string
+	Location string         // the location of the directive, e.g. FIELD_DEFINITION or FIELD
+	Argument string         // the name of the argument for ARGUMENT_DEFINITION
+	Args     map[string]any // the evaluated arguments of the directive
+	Handler  any            // nil for directives applied in a query without a handler
+}
+
+// DirectiveHandlers holds the handlers of the directives applied in the schema by name.
+type DirectiveHandlers struct {
+	Handlers map[string]any
+	// Strict fails for applied directives without a handler.
+	Strict bool
+	// Cost reserves the directives of the cost specification for cost analysis.
+	Cost bool
+}
+
+// IsBuiltinDirective reports whether a directive is defined by the specification or by the
+// library, which are handled by the library.
+func IsBuiltinDirective(name string) bool {
+	_, ok := builtinDirectives[name]
+	return ok
+}
+
+// IsCostDirective reports whether a directive is defined by the cost specification, which is
+// handled by the library if cost analysis is enabled.
+func IsCostDirective(name string) bool {
+	return name == "cost" || name == "listSize"
+}
+
+var builtinDirectives = map[string]struct{}{
+	"include":     {},
+	"skip":        {},
+	"deprecated":  {},
+	"specifiedBy": {},
+	"oneOf":       {},
+	"defer":       {},
+	"stream":      {},
+}
+
+// HasTimeoutDirective reports whether the schema defines the @timeout directive of the library,
+// i.e. with a single argument ms of type Int!. Directives named @timeout with other arguments are
+// ordinary directives, which may have handlers.
+func HasTimeoutDirective(s *ast.Schema) bool {
+	def := s.Directives["timeout"]
+	if def == nil || len(def.Arguments) != 1 {
+		return false
+	}
+	arg := def.Arguments[0]
+	return arg.Name.Name == "ms" && arg.Type.String() == "Int!"
+}
+
+// bindDirectives binds the handlers of the directives applied to the object type of a field, to
+// the field and to its arguments, in this order.
+func (b *execBuilder) bindDirectives(f *Field) error {
+	if b.directives == nil {
+		return nil
+	}
+	if t, ok := b.schema.Types[f.TypeName].(*ast.ObjectTypeDefinition); ok {
+		if err := b.bindDirectiveList(f, t.Directives, "OBJECT", ""); err != nil {
+			return err
+		}
+	}
+	if err := b.bindDirectiveList(f, f.FieldDefinition.Directives, "FIELD_DEFINITION", ""); err != nil {
+		return err
+	}
+	for _, arg := range f.Arguments {
+		if err := b.bindDirectiveList(f, arg.Directives, "ARGUMENT_DEFINITION", arg.Name.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *execBuilder) bindDirectiveList(f *Field, directives ast.DirectiveList, location, argument string) error {
+	for _, d := range directives {
+		name := d.Name.Name
+		if _, ok := builtinDirectives[name]; ok {
+			continue
+		}
+		if name == "timeout" && b.timeouts {
+			continue
+		}
+		h, ok := b.directives.Handlers[name]
+		if IsCostDirective(name) && (b.directives.Cost || !ok) {
+			continue
+		}
+		if !ok {
+			if b.directives.Strict {
+				target := f.TypeName + "." + f.Name
+				if argument != "" {
+					target += "(" + argument + ":)"
+				}
+				return fmt.Errorf("no handler for directive @%s applied to %s", name, target)
+			}
+			continue
+		}
+		f.Directives = append(f.Directives, &Directive{
+			Name:     name,
+			Location: location,
+			Argument: argument,
+			Args:     DirectiveArgs(b.schema.Directives[name], d, nil),
+			Handler:  h,
+		})
+	}
+	return nil
+}
+
+// DirectiveArgs evaluates the arguments of a directive, using the default values of the
+// definition for missing arguments.
+func DirectiveArgs(def *ast.DirectiveDefinition, d *ast.Directive, vars map[string]any) map[string]any {
+	args := make(map[string]any)
+	if def == nil {
+		return args
+	}
+	for _, arg := range def.Arguments {
+		if v, ok := d.Arguments.Get(arg.Name.Name); ok && v != nil {
+			if v, ok := v.(*ast.Variable); ok {
+				if _, ok := vars[v.Name]; !ok {
+					if arg.Default != nil {
+						args[arg.Name.Name] = arg.Default.Deserialize(nil)
+					}
+					continue
+				}
+			}
+			args[arg.Name.Name] = v.Deserialize(vars)
+		} else if arg.Default != nil {
+			args[arg.Name.Name] = arg.Default.Deserialize(nil)
+		}
+	}
+	return args
+}
+
+// timeoutByDirective returns the timeout of the @timeout(ms: Int!) directive of a field.
+func timeoutByDirective(directives ast.DirectiveList) time.Duration {
+	d := directives.Get("timeout")
+	if d == nil {
+		return 0
+	}
+	v, ok := d.Arguments.Get("ms")
+	if !ok || v == nil {
+		return 0
+	}
+	ms, _ := v.Deserialize(nil).(int32)
+	return time.Duration(ms) * time.Millisecond
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/graph-gophers/graphql-go/ast"
 	"github.com/graph-gophers/graphql-go/decode"
@@ -26,6 +27,9 @@ type Schema struct {
 	QueryResolver        reflect.Value
 	MutationResolver     reflect.Value
 	SubscriptionResolver reflect.Value
+	// Directives holds the handlers of directives, which are bound to the directives applied in
+	// queries.
+	Directives *DirectiveHandlers
 }
 
 type Resolvable interface {
@@ -52,6 +56,14 @@ type Field struct {
 	OutputType      reflect.Type
 	Implementations []*FieldImplementation
 	TraceLabel      string
+	// Thunk is true if the resolver returns a func() (T, error), which is called to get the
+	// value of the field once the sibling resolvers have been called.
+	Thunk bool
+	// Directives are the directives applied in the schema which have a handler.
+	Directives []*Directive
+	// Timeout is the timeout of the resolver set by the @timeout directive, if the schema defines
+	// it as @timeout(ms: Int!).
+	Timeout time.Duration
 }
 
 type FieldImplementation struct {
@@ -144,12 +156,14 @@ func (*Object) isResolvable() {}
 func (*List) isResolvable()   {}
 func (*Scalar) isResolvable() {}
 
-func ApplyResolver(s *ast.Schema, resolver any, useFieldResolvers bool) (*Schema, error) {
+func ApplyResolver(s *ast.Schema, resolver any, useFieldResolvers bool, directives *DirectiveHandlers) (*Schema, error) {
 	if resolver == nil {
 		return &Schema{Meta: newMeta(s), Schema: *s}, nil
 	}
 
 	b := newBuilder(s, useFieldResolvers)
+	b.directives = directives
+	b.timeouts = HasTimeoutDirective(s)
 
 	var query, mutation, subscription Resolvable
 
@@ -223,6 +237,7 @@ func ApplyResolver(s *ast.Schema, resolver any, useFieldResolvers bool) (*Schema
 		Query:                query,
 		Mutation:             mutation,
 		Subscription:         subscription,
+		Directives:           directives,
 	}, nil
 }
 
@@ -231,6 +246,8 @@ type execBuilder struct {
 	resMap            map[typePair]*resMapEntry
 	packerBuilder     *packer.Builder
 	useFieldResolvers bool
+	directives        *DirectiveHandlers
+	timeouts          bool // the schema defines the @timeout directive of the library
 }
 
 type typePair struct {
@@ -498,6 +515,7 @@ func (b *execBuilder) populateObjectExec(obj *Object, typeName string, fields as
 				}
 				if needsFallback && field.OutputType == nil {
 					field.OutputType = implField.OutputType
+					field.Thunk = implField.Thunk
 					if err := b.assignExec(&field.ValueExec, field.Type, implField.OutputType); err != nil {
 						return nil, err
 					}
@@ -513,6 +531,16 @@ func (b *execBuilder) populateObjectExec(obj *Object, typeName string, fields as
 		}
 	}
 
+	for _, f := range fields {
+		fe := Fields[f.Name]
+		if b.timeouts {
+			fe.Timeout = timeoutByDirective(f.Directives)
+		}
+		if err := b.bindDirectives(fe); err != nil {
+			return nil, err
+		}
+	}
+
 	ifaces := make(map[string]struct{})
 	for _, iface := range interfaces {
 		ifaces[iface.Name] = struct{}{}
@@ -621,6 +649,10 @@ func (b *execBuilder) makeFieldExec(typeName string, f *ast.FieldDefinition, m r
 	} else {
 		out = sf.Type
 	}
+	if isThunk(out) {
+		fe.Thunk = true
+		out = out.Out(0)
+	}
 
 	fe.OutputType = out
 	if err := b.assignExec(&fe.ValueExec, f.Type, out); err != nil {
@@ -630,6 +662,11 @@ func (b *execBuilder) makeFieldExec(typeName string, f *ast.FieldDefinition, m r
 	return fe, nil
 }
 
+// isThunk reports whether t is a func() (T, error), which resolvers return to defer loading a value.
+func isThunk(t reflect.Type) bool {
+	return t.Kind() == reflect.Func && t.NumIn() == 0 && t.NumOut() == 2 && t.Out(1) == errorType
+}
+
 func findMethod(t reflect.Type, name string) int {
 	for i := 0; i < t.NumMethod(); i++ {
 		if strings.EqualFold(stripUnderscore(name), stripUnderscore(t.Method(i).Name)) {
@@ -21,6 +21,7 @@ type Request struct {
 	Mu                 sync.Mutex
 	Errs               []*errors.QueryError
 	AllowIntrospection bool
+	Incremental        bool // apply @defer and @stream, otherwise they are ignored
 }
 
 func (r *Request) AddError(err *errors.QueryError) {
@@ -39,7 +40,9 @@ func ApplyOperation(r *Request, s *resolvable.Schema, op *ast.OperationDefinitio
 	case query.Subscription:
 		obj = s.Subscription.(*resolvable.Object)
 	}
-	return applySelectionSet(r, s, obj, op.Selections)
+	sels := applySelectionSet(r, s, obj, op.Selections)
+	applyDirectives(sels, queryDirectives(r, s, op.Directives, string(op.Type)))
+	return sels
 }
 
 type Selection interface {
@@ -54,6 +57,18 @@ type SchemaField struct {
 	Sels        []Selection
 	Async       bool
 	FixedResult reflect.Value
+	Stream      *Stream
+	// QueryDirectives are the custom directives applied in the query to the operation, to the
+	// fragments containing the field and to the field, in this order.
+	QueryDirectives []*resolvable.Directive
+	// Locations are the locations of the field in the query.
+	Locations []errors.Location
+}
+
+// Stream holds the arguments of the @stream directive of a list field.
+type Stream struct {
+	Label        string
+	InitialCount int
 }
 
 func (f *SchemaField) Resolve(ctx context.Context, resolver reflect.Value) (reflect.Value, error) {
@@ -70,9 +85,16 @@ type TypenameField struct {
 	Alias string
 }
 
-func (*SchemaField) isSelection()   {}
-func (*TypeAssertion) isSelection() {}
-func (*TypenameField) isSelection() {}
+// DeferredFragment holds the selections of a fragment marked with @defer.
+type DeferredFragment struct {
+	Label string
+	Sels  []Selection
+}
+
+func (*SchemaField) isSelection()      {}
+func (*TypeAssertion) isSelection()    {}
+func (*TypenameField) isSelection()    {}
+func (*DeferredFragment) isSelection() {}
 
 func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, sels []ast.Selection) (flattenedSels []Selection) {
 	for _, sel := range sels {
@@ -100,6 +122,7 @@ func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, s
 						Sels:        applySelectionSet(r, s, s.Meta.Schema, field.SelectionSet),
 						Async:       true,
 						FixedResult: reflect.ValueOf(introspection.WrapSchema(r.Schema)),
+						Locations:   []errors.Location{field.Alias.Loc},
 					})
 				}
 
@@ -124,6 +147,7 @@ func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, s
 						Sels:        applySelectionSet(r, s, s.Type, field.SelectionSet),
 						Async:       true,
 						FixedResult: reflect.ValueOf(resolvedType),
+						Locations:   []errors.Location{field.Alias.Loc},
 					})
 				}
 
@@ -149,12 +173,15 @@ func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, s
 
 				fieldSels := applyField(r, s, fe.ValueExec, field.SelectionSet)
 				flattenedSels = append(flattenedSels, &SchemaField{
-					Field:      *fe,
-					Alias:      field.Alias.Name,
-					Args:       args,
-					PackedArgs: packedArgs,
-					Sels:       fieldSels,
-					Async:      fe.HasContext || fe.ArgsPacker != nil || fe.HasError || HasAsyncSel(fieldSels),
+					Field:           *fe,
+					Alias:           field.Alias.Name,
+					Args:            args,
+					PackedArgs:      packedArgs,
+					Sels:            fieldSels,
+					Async:           fe.HasContext || fe.ArgsPacker != nil || fe.HasError || fe.Thunk || HasAsyncSel(fieldSels),
+					Stream:          streamByDirective(r, field.Directives),
+					QueryDirectives: queryDirectives(r, s, field.Directives, "FIELD"),
+					Locations:       []errors.Location{field.Alias.Loc},
 				})
 			}
 
@@ -163,14 +190,26 @@ func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, s
 			if skipByDirective(r, frag.Directives) {
 				continue
 			}
-			flattenedSels = append(flattenedSels, applyFragment(r, s, e, &frag.Fragment)...)
+			fragSels := applyFragment(r, s, e, &frag.Fragment)
+			applyDirectives(fragSels, queryDirectives(r, s, frag.Directives, "INLINE_FRAGMENT"))
+			if label, ok := deferByDirective(r, frag.Directives); ok {
+				flattenedSels = append(flattenedSels, &DeferredFragment{Label: label, Sels: fragSels})
+				continue
+			}
+			flattenedSels = append(flattenedSels, fragSels...)
 
 		case *ast.FragmentSpread:
 			spread := sel
 			if skipByDirective(r, spread.Directives) {
 				continue
 			}
-			flattenedSels = append(flattenedSels, applyFragment(r, s, e, &r.Doc.Fragments.Get(spread.Name.Name).Fragment)...)
+			fragSels := applyFragment(r, s, e, &r.Doc.Fragments.Get(spread.Name.Name).Fragment)
+			applyDirectives(fragSels, queryDirectives(r, s, spread.Directives, "FRAGMENT_SPREAD"))
+			if label, ok := deferByDirective(r, spread.Directives); ok {
+				flattenedSels = append(flattenedSels, &DeferredFragment{Label: label, Sels: fragSels})
+				continue
+			}
+			flattenedSels = append(flattenedSels, fragSels...)
 
 		default:
 			panic("invalid type")
@@ -289,6 +328,104 @@ func skipByDirective(r *Request, directives ast.DirectiveList) bool {
 	return false
 }
 
+// queryDirectives returns the custom directives of a location in the query with their evaluated
+// arguments.
+func queryDirectives(r *Request, s *resolvable.Schema, directives ast.DirectiveList, location string) []*resolvable.Directive {
+	var ds []*resolvable.Directive
+	for _, d := range directives {
+		name := d.Name.Name
+		if resolvable.IsBuiltinDirective(name) {
+			continue
+		}
+		qd := &resolvable.Directive{
+			Name:     name,
+			Location: location,
+			Args:     resolvable.DirectiveArgs(r.Schema.Directives[name], d, r.Vars),
+		}
+		if s.Directives != nil {
+			qd.Handler = s.Directives.Handlers[name]
+		}
+		ds = append(ds, qd)
+	}
+	return ds
+}
+
+// applyDirectives prepends the directives of a fragment or an operation to the directives of the
+// fields it contains.
+func applyDirectives(sels []Selection, directives []*resolvable.Directive) {
+	if len(directives) == 0 {
+		return
+	}
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *SchemaField:
+			sel.QueryDirectives = append(directives[:len(directives):len(directives)], sel.QueryDirectives...)
+		case *TypeAssertion:
+			applyDirectives(sel.Sels, directives)
+		case *DeferredFragment:
+			applyDirectives(sel.Sels, directives)
+		}
+	}
+}
+
+// deferByDirective reports whether a fragment is deferred by its @defer directive and returns
+// the label of the directive.
+func deferByDirective(r *Request, directives ast.DirectiveList) (string, bool) {
+	d := directives.Get("defer")
+	if d == nil || !r.Incremental || !directiveEnabled(r, d) {
+		return "", false
+	}
+	return directiveLabel(r, d), true
+}
+
+// streamByDirective returns the arguments of the @stream directive of a field, or nil if the
+// field is not streamed.
+func streamByDirective(r *Request, directives ast.DirectiveList) *Stream {
+	d := directives.Get("stream")
+	if d == nil || !r.Incremental || !directiveEnabled(r, d) {
+		return nil
+	}
+	stream := &Stream{Label: directiveLabel(r, d)}
+	if v, ok := d.Arguments.Get("initialCount"); ok {
+		p := packer.ValuePacker{ValueType: reflect.TypeFor[int32]()}
+		n, err := p.Pack(v.Deserialize(r.Vars))
+		if err != nil {
+			r.AddError(errors.Errorf("%s", err))
+			return nil
+		}
+		if n.Int() < 0 {
+			r.AddError(errors.Errorf("initialCount must be a positive integer"))
+			return nil
+		}
+		stream.InitialCount = int(n.Int())
+	}
+	return stream
+}
+
+// directiveEnabled returns the value of the "if" argument of @defer and @stream, which defaults to true.
+func directiveEnabled(r *Request, d *ast.Directive) bool {
+	v, ok := d.Arguments.Get("if")
+	if !ok {
+		return true
+	}
+	p := packer.ValuePacker{ValueType: reflect.TypeFor[bool]()}
+	enabled, err := p.Pack(v.Deserialize(r.Vars))
+	if err != nil {
+		r.AddError(errors.Errorf("%s", err))
+		return false
+	}
+	return enabled.Bool()
+}
+
+func directiveLabel(r *Request, d *ast.Directive) string {
+	v, ok := d.Arguments.Get("label")
+	if !ok {
+		return ""
+	}
+	label, _ := v.Deserialize(r.Vars).(string)
+	return label
+}
+
 func HasAsyncSel(sels []Selection) bool {
 	for _, sel := range sels {
 		switch sel := sel.(type) {
@@ -300,8 +437,8 @@ func HasAsyncSel(sels []Selection) bool {
 			if HasAsyncSel(sel.Sels) {
 				return true
 			}
-		case *TypenameField:
-			// sync
+		case *TypenameField, *DeferredFragment:
+			// sync, deferred fragments are executed separately
 		default:
 			panic("unreachable")
 		}
@@ -73,12 +73,26 @@ func matchArgsRecursive(sel selected.Selection, want, prefix string) (map[string
 				return m, true
 			}
 		}
+	case *selected.DeferredFragment:
+		for _, child := range s.Sels {
+			if m, ok := matchArgsRecursive(child, want, prefix); ok {
+				return m, true
+			}
+		}
 	case *selected.TypenameField:
 		return nil, false
 	}
 	return nil, false
 }
 
+// Selections returns the raw child selections. They must not be modified.
+func (l *Lazy) Selections() []selected.Selection {
+	if l == nil {
+		return nil
+	}
+	return l.raw
+}
+
 // Names returns the deduplicated child field names computing them once.
 func (l *Lazy) Names() []string {
 	if l == nil {
@@ -129,6 +143,8 @@ func collectNestedPaths(dst *[]string, seen map[string]struct{}, prefix string,
 			}
 		case *selected.TypeAssertion:
 			collectNestedPaths(dst, seen, prefix, s.Sels)
+		case *selected.DeferredFragment:
+			collectNestedPaths(dst, seen, prefix, s.Sels)
 		case *selected.TypenameField:
 			continue
 		}
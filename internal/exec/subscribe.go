@@ -28,7 +28,7 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *ast.O
 
 		sels := selected.ApplyOperation(&r.Request, s, op)
 		var fields []*fieldToExec
-		collectFieldsToResolve(sels, s, s.SubscriptionResolver, &fields, make(map[string]*fieldToExec))
+		collectFieldsToResolve(sels, s, s.SubscriptionResolver, &fields, nil, make(map[string]*fieldToExec))
 		f = fields[0]
 
 		var in []reflect.Value
@@ -42,11 +42,17 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *ast.O
 		result = callOut[0]
 
 		if f.field.HasError && !callOut[1].IsNil() {
+			if resolverErr, ok := callOut[1].Interface().(error); ok {
+				if err = r.presentError(ctx, f, nil, resolverErr); err != nil {
+					return
+				}
+			}
 			switch resolverErr := callOut[1].Interface().(type) {
 			case *errors.QueryError:
 				err = resolverErr
 			case error:
 				err = errors.Errorf("%s", resolverErr)
+				err.Locations = f.locations()
 				err.ResolverError = resolverErr
 			default:
 				panic(fmt.Errorf("can only deal with *QueryError and error types, got %T", resolverErr))
@@ -119,6 +125,12 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *ast.O
 					DisableMemoryPooling:    r.DisableMemoryPooling,
 					MaxPooledBufferCapacity: r.MaxPooledBufferCapacity,
 					PanicHandler:            r.PanicHandler,
+					FieldMiddleware:         r.FieldMiddleware,
+					SkipTrivialMiddleware:   r.SkipTrivialMiddleware,
+					DirectiveHandler:        r.DirectiveHandler,
+					FieldTimeouts:           r.FieldTimeouts,
+					ErrorPresenter:          r.ErrorPresenter,
+					PartialData:             r.PartialData,
 				}
 				var out bytes.Buffer
 				func() {
@@ -127,7 +139,7 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *ast.O
 						timeout = time.Second
 					}
 
-					subCtx, cancel := context.WithTimeout(ctx, timeout)
+					subCtx, cancel := context.WithTimeout(withLoaders(ctx, r.Tracer), timeout)
 					defer cancel()
 
 					// resolve response
@@ -136,7 +148,7 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *ast.O
 
 						buf := subR.acquireBuffer()
 						defer subR.releaseBuffer(buf)
-						subR.execSelectionSet(subCtx, f.sels, f.field.Type, &pathSegment{nil, f.field.Alias}, s, resp, buf)
+						subR.execSelectionSet(subCtx, f.sels, f.field.Type, fieldPath(nil, f), s, resp, buf)
 
 						propagateChildError := false
 						if _, nonNullChild := f.field.Type.(*ast.NonNull); nonNullChild && resolvedToNull(buf) {
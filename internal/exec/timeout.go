@@ -0,0 +1,89 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/internal/exec/selected"
+)
+
+// FieldKey identifies a field of a type.
+type FieldKey struct {
+	TypeName  string
+	FieldName string
+}
+
+// timeoutError is returned for fields whose resolver did not return in time.
+type timeoutError struct {
+	field   *selected.SchemaField
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("graphql: field %s.%s timed out after %s", e.field.TypeName, e.field.Name, e.timeout)
+}
+
+// Public reports that the message of the error may be shown to clients, even if error messages
+// are masked by the error presenter.
+func (e *timeoutError) Public() bool {
+	return true
+}
+
+func (e *timeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// fieldTimeout returns the timeout of a field, which is configured by the request or by the
+// @timeout directive of the field.
+func (r *Request) fieldTimeout(f *selected.SchemaField) time.Duration {
+	if r.FieldTimeouts != nil {
+		if timeout, ok := r.FieldTimeouts[FieldKey{f.TypeName, f.Name}]; ok {
+			return timeout
+		}
+	}
+	return f.Timeout
+}
+
+// resolveFieldWithTimeout resolves a field like resolveField, but returns a timeout error if the
+// resolution takes longer than the timeout. The context of the resolver is cancelled then, but the
+// resolver is not waited for. Panics of the resolver after the timeout are logged.
+func (r *Request) resolveFieldWithTimeout(ctx context.Context, f *fieldToExec, path *pathSegment, timeout time.Duration) (reflect.Value, error) {
+	timeoutErr := &timeoutError{field: f.field, timeout: timeout}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, timeoutErr)
+	defer cancel()
+
+	type resolved struct {
+		result     reflect.Value
+		err        error
+		panicValue any
+	}
+	done := make(chan resolved, 1)
+	go func() {
+		var res resolved
+		defer func() {
+			res.panicValue = recover()
+			done <- res
+		}()
+		res.result, res.err = r.resolveField(ctx, f, path)
+	}()
+
+	select {
+	case res := <-done:
+		if res.panicValue != nil {
+			panic(res.panicValue)
+		}
+		return res.result, res.err
+	case <-ctx.Done():
+		// A panic of the abandoned resolver can no longer be reported in the response, so it is
+		// logged once the resolver returns.
+		go func() {
+			if res := <-done; res.panicValue != nil {
+				r.Logger.LogPanic(ctx, res.panicValue)
+			}
+		}()
+		// The request may have been cancelled before the field timed out.
+		return reflect.Value{}, context.Cause(ctx)
+	}
+}
@@ -25,6 +25,8 @@ func collectFieldsToValidate(sels []selected.Selection, s *resolvable.Schema, fi
 			// Ignore __typename, which has no directives
 		case *selected.TypeAssertion:
 			collectFieldsToValidate(sel.Sels, s, fields, fieldByAlias)
+		case *selected.DeferredFragment:
+			collectFieldsToValidate(sel.Sels, s, fields, fieldByAlias)
 		default:
 			panic(fmt.Sprintf("unexpected selection type %T", sel))
 		}
@@ -65,7 +67,7 @@ func validateSelections(ctx context.Context, sels []selected.Selection, path *pa
 	collectFieldsToValidate(sels, s, &fields, make(map[string]*fieldToValidate))
 
 	for _, f := range fields {
-		errs = append(errs, validateFieldSelection(ctx, s, f, &pathSegment{path, f.field.Alias})...)
+		errs = append(errs, validateFieldSelection(ctx, s, f, &pathSegment{parent: path, value: f.field.Alias})...)
 	}
 
 	return errs
@@ -73,5 +75,5 @@ func validateSelections(ctx context.Context, sels []selected.Selection, path *pa
 
 func validateList(ctx context.Context, sels []selected.Selection, typ *ast.List, path *pathSegment, s *resolvable.Schema) []*errors.QueryError {
 	// For lists, we only need to apply validation once. Nothing has been evaluated, so we have no list, and need to use '0' as the path index
-	return validateSelectionSet(ctx, sels, typ.OfType, &pathSegment{path, 0}, s)
+	return validateSelectionSet(ctx, sels, typ.OfType, &pathSegment{parent: path, value: 0}, s)
 }
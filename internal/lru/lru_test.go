@@ -0,0 +1,33 @@
+package lru_test
+
+import (
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/internal/lru"
+)
+
+func TestCache(t *testing.T) {
+	c := lru.New[string, int](2)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected a=1, got %d, %v", v, ok)
+	}
+
+	// "b" is the least recently used entry.
+	c.Add("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("expected a=1, got %d, %v", v, ok)
+	}
+
+	c.Add("c", 4)
+	if v, _ := c.Get("c"); v != 4 {
+		t.Errorf("expected c=4, got %d", v)
+	}
+	if n := c.Len(); n != 2 {
+		t.Errorf("expected 2 entries, got %d", n)
+	}
+}
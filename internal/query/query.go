@@ -16,7 +16,14 @@ const (
 )
 
 func Parse(queryString string) (*ast.ExecutableDefinition, *errors.QueryError) {
+	return ParseWithMaxTokens(queryString, 0)
+}
+
+// ParseWithMaxTokens parses a query like Parse, but stops with an error once more than maxTokens
+// tokens are consumed. A maxTokens of 0 disables the limit.
+func ParseWithMaxTokens(queryString string, maxTokens int) (*ast.ExecutableDefinition, *errors.QueryError) {
 	l := common.NewLexer(queryString, true)
+	l.SetMaxTokens(maxTokens)
 
 	var execDef *ast.ExecutableDefinition
 	err := l.CatchSyntaxError(func() { execDef = parseExecutableDefinition(l) })
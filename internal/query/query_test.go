@@ -71,3 +71,24 @@ func TestParseRejectsInvalidSurrogatePairEscape(t *testing.T) {
 		t.Fatal("expected syntax error for invalid surrogate pair, got nil")
 	}
 }
+
+func TestParseWithMaxTokens(t *testing.T) {
+	t.Parallel()
+
+	// Commas and comments are not tokens.
+	const q = "query Q { a, b # comment\n c }"
+	if _, err := ParseWithMaxTokens(q, 7); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := ParseWithMaxTokens(q, 6)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if want := "graphql: Document exceeds the maximum of 6 tokens. (line 2, column 4)"; err.Error() != want {
+		t.Errorf("wrong error\nwant: %s\ngot:  %s", want, err)
+	}
+	if err.Rule != "MaxTokensExceeded" || err.Extensions["code"] != "MAX_TOKENS_EXCEEDED" {
+		t.Errorf("wrong rule or code: %q, %v", err.Rule, err.Extensions)
+	}
+}
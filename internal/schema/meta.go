@@ -22,6 +22,32 @@ func newMeta() *ast.Schema {
 	return s
 }
 
+// AddIncrementalDirectives adds the @defer and @stream directives of incremental delivery to a
+// schema created with [New].
+func AddIncrementalDirectives(s *ast.Schema) error {
+	return Parse(s, incrementalSrc, false)
+}
+
+var incrementalSrc = `
+	# Directs the executor to deliver this fragment incrementally when the ` + "`" + `if` + "`" + ` argument is true.
+	directive @defer(
+		# Deferred when true.
+		if: Boolean! = true
+		# Identifies the deferred result in subsequent payloads.
+		label: String
+	) on FRAGMENT_SPREAD | INLINE_FRAGMENT
+
+	# Directs the executor to deliver the items of this list field incrementally when the ` + "`" + `if` + "`" + ` argument is true.
+	directive @stream(
+		# Streamed when true.
+		if: Boolean! = true
+		# Identifies the streamed items in subsequent payloads.
+		label: String
+		# The number of list items delivered in the initial payload.
+		initialCount: Int! = 0
+	) on FIELD
+`
+
 var metaSrc = `
 	# The ` + "`" + `Int` + "`" + ` scalar type represents non-fractional signed whole numeric values. Int can represent values between -(2^31) and 2^31 - 1.
 	scalar Int
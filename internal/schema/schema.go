@@ -27,12 +27,21 @@ func New() *ast.Schema {
 }
 
 func Parse(s *ast.Schema, schemaString string, useStringDescriptions bool) error {
+	builtin := maps.Clone(s.Directives)
 	l := common.NewLexer(schemaString, useStringDescriptions)
 	err := l.CatchSyntaxError(func() { parseSchema(s, l) })
 	if err != nil {
 		return err
 	}
 
+	// Directives defined before parsing, e.g. @include and @skip, are part of the executor and must
+	// not be replaced by a definition with different arguments or locations.
+	for _, name := range slices.Sorted(maps.Keys(builtin)) {
+		if s.Directives[name] != builtin[name] {
+			return errors.Errorf("built-in directive %q can not be redefined", name)
+		}
+	}
+
 	if err := mergeExtensions(s); err != nil {
 		return err
 	}
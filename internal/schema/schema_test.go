@@ -1412,6 +1412,22 @@ func TestInterfaceImplementsInterface(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "Rejects redefinition of a built-in directive",
+			sdl: `
+			directive @skip(unless: Boolean!) on FIELD
+			type Query {
+				hello: String
+			}
+			`,
+			validateError: func(err error) error {
+				msg := `graphql: built-in directive "skip" can not be redefined`
+				if err == nil || err.Error() != msg {
+					return fmt.Errorf("expected error %q, but got %q", msg, err)
+				}
+				return nil
+			},
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			s, err := schema.ParseSchema(tt.sdl, tt.useStringDescriptions)
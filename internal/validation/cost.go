@@ -0,0 +1,329 @@
+package validation
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/internal/query"
+)
+
+// defaultListSize is the assumed size of lists without a @listSize directive or slicing arguments.
+const defaultListSize = 10
+
+// mutationFieldWeight is the default weight of the root fields of mutations.
+const mutationFieldWeight = 10
+
+// MaxCostValue is the ceiling of the computed costs. Every sum and product is capped at it, so
+// that the cost of deeply nested lists cannot overflow.
+const MaxCostValue = math.MaxInt32
+
+// Cost computes the static cost of an operation with the variables applied, following the cost
+// directives of the GraphQL Foundation cost specification:
+//
+//	directive @cost(weight: String!) on ARGUMENT_DEFINITION | ENUM_VALUE | FIELD_DEFINITION | INPUT_FIELD_DEFINITION | OBJECT | SCALAR
+//	directive @listSize(assumedSize: Int, slicingArguments: [String!], sizedFields: [String!], requireOneSlicingArgument: Boolean = true) on FIELD_DEFINITION
+//
+// The weight of a field defaults to the weight of its type, which is 1 for composite types and 0
+// for scalars and enums. The weight of the root fields of mutations defaults to 10. The cost of a
+// list field is the cost of an item multiplied by the size of the list, which is the largest value
+// of its slicing arguments, its assumed size or 10. For fragments on different types of an
+// abstract type, the cost of the most expensive type is counted. The cost of each fragment is
+// computed once per type and list sizes, and the cost is capped at [MaxCostValue].
+//
+// The operation must be valid.
+func Cost(s *ast.Schema, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, vars map[string]any) float64 {
+	var t ast.NamedType
+	switch op.Type {
+	case query.Query:
+		t = s.RootOperationTypes["query"]
+	case query.Mutation:
+		t = s.RootOperationTypes["mutation"]
+	case query.Subscription:
+		t = s.RootOperationTypes["subscription"]
+	}
+	if t == nil {
+		return 0
+	}
+	c := &costContext{schema: s, doc: doc, vars: vars, fragments: make(map[fragmentCostKey]float64)}
+	return math.Ceil(c.selectionSetCost(t, op.Selections, op.Type == query.Mutation, nil))
+}
+
+type costContext struct {
+	schema    *ast.Schema
+	doc       *ast.ExecutableDefinition
+	vars      map[string]any
+	fragments map[fragmentCostKey]float64 // the cost of fragment spreads
+}
+
+// sizedFields holds the list sizes of the fields named by the sizedFields of a parent field.
+type sizedFields struct {
+	sizes map[string]float64
+}
+
+func (sf *sizedFields) get(name string) (float64, bool) {
+	if sf == nil {
+		return 0, false
+	}
+	size, ok := sf.sizes[name]
+	return size, ok
+}
+
+// fragmentCostKey identifies the cost of a fragment spread, which depends on the type it is
+// spread on and on the list sizes of the parent field.
+type fragmentCostKey struct {
+	t        ast.NamedType
+	frag     *ast.FragmentDefinition
+	mutation bool
+	sizes    *sizedFields
+}
+
+// capCost caps a cost at MaxCostValue.
+func capCost(cost float64) float64 {
+	return min(cost, MaxCostValue)
+}
+
+// selectionSetCost returns the cost of the selections on a type. The sizes are the list sizes of
+// the fields named by the sizedFields of the parent field.
+func (c *costContext) selectionSetCost(t ast.NamedType, sels []ast.Selection, mutation bool, sizes *sizedFields) float64 {
+	var cost float64
+	var typed map[string]float64 // the cost of fragments on other types
+	addFragment := func(on string, sels []ast.Selection, frag *ast.FragmentDefinition) {
+		fragType := t
+		if on != "" && on != t.TypeName() {
+			fragType = c.schema.Types[on]
+		}
+		var fragCost float64
+		if frag == nil {
+			fragCost = c.selectionSetCost(fragType, sels, mutation, sizes)
+		} else {
+			key := fragmentCostKey{t: fragType, frag: frag, mutation: mutation, sizes: sizes}
+			var ok bool
+			if fragCost, ok = c.fragments[key]; !ok {
+				fragCost = c.selectionSetCost(fragType, sels, mutation, sizes)
+				c.fragments[key] = fragCost
+			}
+		}
+		if _, ok := fragType.(*ast.ObjectTypeDefinition); !ok || fragType == t {
+			cost = capCost(cost + fragCost)
+			return
+		}
+		if typed == nil {
+			typed = make(map[string]float64)
+		}
+		typed[on] = capCost(typed[on] + fragCost)
+	}
+
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if !c.included(sel.Directives) {
+				continue
+			}
+			cost = capCost(cost + c.fieldCost(t, sel, mutation, sizes))
+		case *ast.InlineFragment:
+			if !c.included(sel.Directives) {
+				continue
+			}
+			addFragment(sel.On.Name, sel.Selections, nil)
+		case *ast.FragmentSpread:
+			if !c.included(sel.Directives) {
+				continue
+			}
+			if frag := c.doc.Fragments.Get(sel.Name.Name); frag != nil {
+				addFragment(frag.On.Name, frag.Selections, frag)
+			}
+		}
+	}
+
+	var maxTyped float64
+	for _, fragCost := range typed {
+		maxTyped = max(maxTyped, fragCost)
+	}
+	return capCost(cost + maxTyped)
+}
+
+func (c *costContext) fieldCost(t ast.NamedType, f *ast.Field, mutation bool, sizes *sizedFields) float64 {
+	var def *ast.FieldDefinition
+	switch t := t.(type) {
+	case *ast.ObjectTypeDefinition:
+		def = t.Fields.Get(f.Name.Name)
+	case *ast.InterfaceTypeDefinition:
+		def = t.Fields.Get(f.Name.Name)
+	}
+	if def == nil {
+		// Meta fields are free.
+		return 0
+	}
+
+	var cost float64
+	for _, arg := range f.Arguments {
+		if argDef := def.Arguments.Get(arg.Name.Name); argDef != nil {
+			w, _ := costWeight(argDef.Directives)
+			cost = capCost(cost + w)
+		}
+	}
+
+	fieldType, isList := unwrapList(def.Type)
+	weight, ok := costWeight(def.Directives)
+	if !ok {
+		weight = typeWeight(fieldType)
+		if mutation {
+			weight = mutationFieldWeight
+		}
+	}
+
+	listSize := def.Directives.Get("listSize")
+	var childSizes *sizedFields
+	if listSize != nil {
+		if v, ok := directiveArg(listSize, "sizedFields"); ok {
+			if names, ok := v.Deserialize(nil).([]any); ok {
+				size := c.listSize(listSize, f)
+				childSizes = &sizedFields{sizes: make(map[string]float64, len(names))}
+				for _, name := range names {
+					if name, ok := name.(string); ok {
+						childSizes.sizes[name] = size
+					}
+				}
+			}
+		}
+	}
+
+	itemCost := capCost(weight + c.selectionSetCost(fieldType, f.SelectionSet, false, childSizes))
+	switch size, sized := sizes.get(f.Name.Name); {
+	case sized:
+		return capCost(cost + capCost(size*itemCost))
+	case isList && childSizes == nil:
+		return capCost(cost + capCost(c.listSize(listSize, f)*itemCost))
+	default:
+		return capCost(cost + itemCost)
+	}
+}
+
+// listSize returns the size of a list field from its slicing arguments or its assumed size.
+func (c *costContext) listSize(d *ast.Directive, f *ast.Field) float64 {
+	if d == nil {
+		return defaultListSize
+	}
+	if v, ok := directiveArg(d, "slicingArguments"); ok {
+		names, _ := v.Deserialize(nil).([]any)
+		size, sliced := 0.0, false
+		for _, name := range names {
+			name, _ := name.(string)
+			arg, ok := f.Arguments.Get(name)
+			if !ok {
+				continue
+			}
+			if n, ok := number(arg.Deserialize(c.vars)); ok {
+				size, sliced = max(size, n), true
+			}
+		}
+		if sliced {
+			return size
+		}
+	}
+	if v, ok := directiveArg(d, "assumedSize"); ok {
+		if n, ok := number(v.Deserialize(c.vars)); ok {
+			return n
+		}
+	}
+	return defaultListSize
+}
+
+// included evaluates the @skip and @include directives.
+func (c *costContext) included(directives ast.DirectiveList) bool {
+	if d := directives.Get("skip"); d != nil {
+		if v, ok := d.Arguments.Get("if"); ok && v.Deserialize(c.vars) == true {
+			return false
+		}
+	}
+	if d := directives.Get("include"); d != nil {
+		if v, ok := d.Arguments.Get("if"); ok && v.Deserialize(c.vars) == false {
+			return false
+		}
+	}
+	return true
+}
+
+// typeWeight returns the weight of a named type, which defaults to 1 for composite types and 0
+// for scalars and enums.
+func typeWeight(t ast.NamedType) float64 {
+	var directives ast.DirectiveList
+	composite := false
+	switch t := t.(type) {
+	case *ast.ObjectTypeDefinition:
+		directives, composite = t.Directives, true
+	case *ast.InterfaceTypeDefinition:
+		directives, composite = t.Directives, true
+	case *ast.Union:
+		directives, composite = t.Directives, true
+	case *ast.ScalarTypeDefinition:
+		directives = t.Directives
+	case *ast.EnumTypeDefinition:
+		directives = t.Directives
+	}
+	if w, ok := costWeight(directives); ok {
+		return w
+	}
+	if composite {
+		return 1
+	}
+	return 0
+}
+
+// costWeight returns the weight of a @cost directive.
+func costWeight(directives ast.DirectiveList) (float64, bool) {
+	d := directives.Get("cost")
+	if d == nil {
+		return 0, false
+	}
+	v, ok := directiveArg(d, "weight")
+	if !ok {
+		return 0, false
+	}
+	switch w := v.Deserialize(nil).(type) {
+	case string:
+		f, err := strconv.ParseFloat(w, 64)
+		return f, err == nil && !math.IsNaN(f) && !math.IsInf(f, 0)
+	default:
+		return number(w)
+	}
+}
+
+// directiveArg returns the value of an argument of a directive. Arguments of directives applied
+// in the schema without a value and without a default value are nil.
+func directiveArg(d *ast.Directive, name string) (ast.Value, bool) {
+	v, ok := d.Arguments.Get(name)
+	return v, ok && v != nil
+}
+
+func unwrapList(t ast.Type) (ast.NamedType, bool) {
+	isList := false
+	for {
+		switch tt := t.(type) {
+		case *ast.NonNull:
+			t = tt.OfType
+		case *ast.List:
+			t, isList = tt.OfType, true
+		case ast.NamedType:
+			return tt, isList
+		default:
+			return nil, isList
+		}
+	}
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
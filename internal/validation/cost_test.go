@@ -0,0 +1,158 @@
+package validation_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/internal/query"
+	"github.com/graph-gophers/graphql-go/internal/schema"
+	"github.com/graph-gophers/graphql-go/internal/validation"
+)
+
+const costSchema = `
+	directive @cost(weight: String!) on ARGUMENT_DEFINITION | ENUM_VALUE | FIELD_DEFINITION | INPUT_FIELD_DEFINITION | OBJECT | SCALAR
+	directive @listSize(assumedSize: Int, slicingArguments: [String!], sizedFields: [String!], requireOneSlicingArgument: Boolean = true) on FIELD_DEFINITION
+
+	schema {
+		query: Query
+		mutation: Mutation
+	}
+
+	type Query {
+		name: String
+		expensive: String @cost(weight: "5")
+		user(id: ID!): User
+		users(first: Int, last: Int): [User!]! @listSize(slicingArguments: ["first", "last"], assumedSize: 50)
+		tags: [String!]!
+		search(query: String! @cost(weight: "3")): [SearchResult!]! @listSize(assumedSize: 2)
+		friendsConnection(first: Int!): FriendsConnection! @listSize(slicingArguments: ["first"], sizedFields: ["edges"])
+	}
+
+	type Mutation {
+		like(id: ID!): User
+	}
+
+	type User {
+		name: String
+		avatar: Image
+	}
+
+	type Image @cost(weight: "2.5") {
+		url: String
+	}
+
+	type Post {
+		title: String
+		author: User
+		comments: [User!]! @listSize(assumedSize: 3)
+	}
+
+	union SearchResult = User | Post
+
+	type FriendsConnection {
+		edges: [FriendEdge!]!
+	}
+
+	type FriendEdge {
+		node: User
+	}
+`
+
+func TestCost(t *testing.T) {
+	s := schema.New()
+	if err := schema.Parse(s, costSchema, false); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		vars  map[string]any
+		want  float64
+	}{
+		{name: "scalars", query: `{ name __typename }`, want: 0},
+		{name: "field weight", query: `{ expensive }`, want: 5},
+		{name: "object", query: `{ user(id: 1) { name } }`, want: 1},
+		{name: "type weight", query: `{ user(id: 1) { avatar { url } } }`, want: 4},
+		{name: "default list size", query: `{ tags }`, want: 0},
+		{name: "assumed size", query: `{ users { name } }`, want: 50},
+		{name: "slicing argument", query: `{ users(first: 2) { avatar { url } } }`, want: 2 * 3.5},
+		{name: "largest slicing argument", query: `{ users(first: 5, last: 7) { name } }`, want: 7},
+		{name: "slicing variable", query: `query($n: Int) { users(first: $n) { name } }`, vars: map[string]any{"n": 4}, want: 4},
+		{name: "missing slicing variable", query: `query($n: Int) { users(first: $n) { name } }`, want: 50},
+		{name: "argument weight", query: `{ search(query: "a") { ... on User { name } } }`, want: 3 + 2},
+		{name: "most expensive type", query: `{ search(query: "a") { ... on User { avatar { url } } ... on Post { comments { name } } } }`, want: 3 + 2*(1+3)},
+		{name: "fragment spreads", query: `{ ...q } fragment q on Query { user(id: 1) { ...u } } fragment u on User { avatar { url } }`, want: 4},
+		{name: "sized fields", query: `{ friendsConnection(first: 3) { edges { node { name } } } }`, want: 1 + 3*(1+1)},
+		{name: "skip", query: `query($skip: Boolean!) { user(id: 1) @skip(if: $skip) { name } expensive @include(if: false) }`, vars: map[string]any{"skip": true}, want: 0},
+		{name: "mutation", query: `mutation { like(id: 1) { avatar { url } } }`, want: 10 + 3},
+		{name: "rounded up", query: `{ users(first: 3) { avatar { url } } }`, want: 11},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := query.Parse(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if errs := validation.Validate(s, d, tc.vars, 0, 0, false); len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			if got := validation.Cost(s, d, d.Operations[0], tc.vars); got != tc.want {
+				t.Errorf("wrong cost: want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+const nestedCostSchema = `
+	directive @listSize(assumedSize: Int, slicingArguments: [String!], sizedFields: [String!], requireOneSlicingArgument: Boolean = true) on FIELD_DEFINITION
+
+	type Query {
+		items(first: Int): [Item!]! @listSize(slicingArguments: ["first"])
+	}
+
+	type Item {
+		name: String
+		items(first: Int): [Item!]! @listSize(slicingArguments: ["first"])
+	}
+`
+
+func TestCostLimits(t *testing.T) {
+	s := schema.New()
+	if err := schema.Parse(s, nestedCostSchema, false); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("capped", func(t *testing.T) {
+		q := strings.Repeat("items(first: 2147483647) { ", 40) + "name" + strings.Repeat(" }", 40)
+		d, err := query.Parse("{ " + q + " }")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := validation.Cost(s, d, d.Operations[0], nil); got != validation.MaxCostValue {
+			t.Errorf("wrong cost: want %v, got %v", float64(validation.MaxCostValue), got)
+		}
+	})
+
+	t.Run("fragment chain", func(t *testing.T) {
+		// Each fragment spreads the next one twice, so the fragments expand to 2^26 fields.
+		var b strings.Builder
+		b.WriteString("{ items { ...f0 } }")
+		for i := range 26 {
+			fmt.Fprintf(&b, " fragment f%d on Item { items { ...f%d ...f%d } }", i, i+1, i+1)
+		}
+		b.WriteString(" fragment f26 on Item { name }")
+		d, err := query.Parse(b.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := time.Now()
+		if got := validation.Cost(s, d, d.Operations[0], nil); got != validation.MaxCostValue {
+			t.Errorf("wrong cost: want %v, got %v", float64(validation.MaxCostValue), got)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("computing the cost took %s", elapsed)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package validation
+
+import (
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// Limits restrict the size of the operations of a document. A limit of 0 disables the limit.
+type Limits struct {
+	// MaxAliases is the maximum number of aliased fields of an operation, including the fields of
+	// the fragments it spreads.
+	MaxAliases int
+	// MaxRootFields is the maximum number of root fields of an operation, including the root
+	// fields of the fragments it spreads.
+	MaxRootFields int
+	// MaxDirectivesPerField is the maximum number of directives of a field.
+	MaxDirectivesPerField int
+	// MaxFragments is the maximum number of fragment definitions and inline fragments of a
+	// document.
+	MaxFragments int
+}
+
+// ValidateLimits checks the limits of a parsed document. It does not depend on the schema and
+// is cheap compared to the other rules, so it can run before them.
+func ValidateLimits(doc *ast.ExecutableDefinition, limits Limits) []*errors.QueryError {
+	c := &context{doc: doc}
+	if limits.MaxFragments > 0 || limits.MaxDirectivesPerField > 0 {
+		w := &limitsWalker{c: c, limits: limits, fragments: len(doc.Fragments)}
+		if limits.MaxFragments > 0 && w.fragments > limits.MaxFragments {
+			w.fragmentsExceeded(doc.Fragments[limits.MaxFragments].Loc)
+		}
+		for _, op := range doc.Operations {
+			w.walk(op.Selections)
+		}
+		for _, frag := range doc.Fragments {
+			w.walk(frag.Selections)
+		}
+	}
+
+	for _, op := range doc.Operations {
+		if limits.MaxRootFields > 0 {
+			counter := &selectionCounter{doc: doc, limit: limits.MaxRootFields, memo: make(map[*ast.FragmentDefinition]int), countField: func(*ast.Field) bool { return true }}
+			if counter.count(op.Selections) > limits.MaxRootFields {
+				c.addLimitErr(op.Loc, "MaxRootFieldsExceeded", "MAX_ROOT_FIELDS_EXCEEDED", "Operation exceeds the maximum of %d root fields.", limits.MaxRootFields)
+			}
+		}
+		if limits.MaxAliases > 0 {
+			counter := &selectionCounter{doc: doc, limit: limits.MaxAliases, memo: make(map[*ast.FragmentDefinition]int), countField: hasAlias, nested: true}
+			if counter.count(op.Selections) > limits.MaxAliases {
+				c.addLimitErr(op.Loc, "MaxAliasesExceeded", "MAX_ALIASES_EXCEEDED", "Operation exceeds the maximum of %d aliases.", limits.MaxAliases)
+			}
+		}
+	}
+	return c.errs
+}
+
+func (c *context) addLimitErr(loc errors.Location, rule, code, format string, a ...any) {
+	c.addErr(loc, rule, format, a...)
+	c.errs[len(c.errs)-1].Extensions = map[string]any{"code": code}
+}
+
+// limitsWalker checks the limits of each selection of a document once, without following
+// fragment spreads.
+type limitsWalker struct {
+	c         *context
+	limits    Limits
+	fragments int
+	// Each limit is reported once.
+	fragmentsReported  bool
+	directivesReported bool
+}
+
+func (w *limitsWalker) walk(sels []ast.Selection) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if n := w.limits.MaxDirectivesPerField; n > 0 && len(sel.Directives) > n && !w.directivesReported {
+				w.directivesReported = true
+				w.c.addLimitErr(sel.Alias.Loc, "MaxDirectivesPerFieldExceeded", "MAX_DIRECTIVES_PER_FIELD_EXCEEDED", "Field %q has %d directives that exceeds max directives per field %d", sel.Name.Name, len(sel.Directives), n)
+			}
+			w.walk(sel.SelectionSet)
+		case *ast.InlineFragment:
+			w.fragments++
+			if w.limits.MaxFragments > 0 && w.fragments > w.limits.MaxFragments {
+				w.fragmentsExceeded(sel.Loc)
+			}
+			w.walk(sel.Selections)
+		}
+	}
+}
+
+func (w *limitsWalker) fragmentsExceeded(loc errors.Location) {
+	if w.fragmentsReported {
+		return
+	}
+	w.fragmentsReported = true
+	w.c.addLimitErr(loc, "MaxFragmentsExceeded", "MAX_FRAGMENTS_EXCEEDED", "Document exceeds the maximum of %d fragments.", w.limits.MaxFragments)
+}
+
+// selectionCounter counts the fields of selections, following fragment spreads. Counts are
+// capped at limit+1, since the fields of a fragment spread many times can grow exponentially.
+type selectionCounter struct {
+	doc        *ast.ExecutableDefinition
+	limit      int
+	memo       map[*ast.FragmentDefinition]int
+	countField func(*ast.Field) bool
+	nested     bool // count the fields of selection sets of fields
+}
+
+func (sc *selectionCounter) count(sels []ast.Selection) int {
+	n := 0
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if sc.countField(sel) {
+				n = sc.add(n, 1)
+			}
+			if sc.nested {
+				n = sc.add(n, sc.count(sel.SelectionSet))
+			}
+		case *ast.InlineFragment:
+			n = sc.add(n, sc.count(sel.Selections))
+		case *ast.FragmentSpread:
+			frag := sc.doc.Fragments.Get(sel.Name.Name)
+			if frag == nil {
+				continue
+			}
+			m, ok := sc.memo[frag]
+			if !ok {
+				// Guard against fragment cycles, which are reported by the other rules.
+				sc.memo[frag] = 0
+				m = sc.count(frag.Selections)
+				sc.memo[frag] = m
+			}
+			n = sc.add(n, m)
+		}
+		if n > sc.limit {
+			return n
+		}
+	}
+	return n
+}
+
+func (sc *selectionCounter) add(a, b int) int {
+	return min(a+b, sc.limit+1)
+}
+
+// hasAlias reports whether a field has an alias which is not its name.
+func hasAlias(f *ast.Field) bool {
+	return f.Alias.Loc != f.Name.Loc
+}
@@ -0,0 +1,123 @@
+package validation_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/internal/query"
+	"github.com/graph-gophers/graphql-go/internal/validation"
+)
+
+func TestValidateLimits(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		limits validation.Limits
+		query  string
+		rule   string
+		code   string
+		want   string
+	}{
+		{
+			name:   "aliases",
+			limits: validation.Limits{MaxAliases: 2},
+			query:  `{ a: name b: name name }`,
+		},
+		{
+			name:   "too many aliases",
+			limits: validation.Limits{MaxAliases: 2},
+			query:  `{ a: name user { b: name c: name } }`,
+			rule:   "MaxAliasesExceeded",
+			code:   "MAX_ALIASES_EXCEEDED",
+			want:   "Operation exceeds the maximum of 2 aliases.",
+		},
+		{
+			name:   "aliases in fragments",
+			limits: validation.Limits{MaxAliases: 3},
+			query:  `{ ...f ...f } fragment f on Query { a: name b: name }`,
+			rule:   "MaxAliasesExceeded",
+			code:   "MAX_ALIASES_EXCEEDED",
+			want:   "Operation exceeds the maximum of 3 aliases.",
+		},
+		{
+			name:   "fragment bomb",
+			limits: validation.Limits{MaxAliases: 100},
+			query:  `{ ...f1 } ` + fragmentBomb(40),
+			rule:   "MaxAliasesExceeded",
+			code:   "MAX_ALIASES_EXCEEDED",
+			want:   "Operation exceeds the maximum of 100 aliases.",
+		},
+		{
+			name:   "root fields",
+			limits: validation.Limits{MaxRootFields: 3},
+			query:  `{ a: name user { name id } ... on Query { name } }`,
+		},
+		{
+			name:   "too many root fields",
+			limits: validation.Limits{MaxRootFields: 3},
+			query:  `{ a: name ... on Query { name } ...f } fragment f on Query { b: name c: name }`,
+			rule:   "MaxRootFieldsExceeded",
+			code:   "MAX_ROOT_FIELDS_EXCEEDED",
+			want:   "Operation exceeds the maximum of 3 root fields.",
+		},
+		{
+			name:   "directives",
+			limits: validation.Limits{MaxDirectivesPerField: 2},
+			query:  `{ name @skip(if: false) @include(if: true) }`,
+		},
+		{
+			name:   "too many directives",
+			limits: validation.Limits{MaxDirectivesPerField: 2},
+			query:  `{ user { name @skip(if: false) @skip(if: false) @skip(if: false) } }`,
+			rule:   "MaxDirectivesPerFieldExceeded",
+			code:   "MAX_DIRECTIVES_PER_FIELD_EXCEEDED",
+			want:   `Field "name" has 3 directives that exceeds max directives per field 2`,
+		},
+		{
+			name:   "fragments",
+			limits: validation.Limits{MaxFragments: 2},
+			query:  `{ ...f ... on Query { name } } fragment f on Query { name }`,
+		},
+		{
+			name:   "too many fragments",
+			limits: validation.Limits{MaxFragments: 2},
+			query:  `{ ...f ... on Query { name ... on Query { name } } } fragment f on Query { name }`,
+			rule:   "MaxFragmentsExceeded",
+			code:   "MAX_FRAGMENTS_EXCEEDED",
+			want:   "Document exceeds the maximum of 2 fragments.",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := query.Parse(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs := validation.ValidateLimits(d, tc.limits)
+			if tc.want == "" {
+				if len(errs) > 0 {
+					t.Errorf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("want 1 error, got %v", errs)
+			}
+			if errs[0].Message != tc.want || errs[0].Rule != tc.rule {
+				t.Errorf("wrong error\nwant: %s (%s)\ngot:  %s (%s)", tc.want, tc.rule, errs[0].Message, errs[0].Rule)
+			}
+			if errs[0].Extensions["code"] != tc.code {
+				t.Errorf("wrong code: want %s, got %v", tc.code, errs[0].Extensions["code"])
+			}
+		})
+	}
+}
+
+// fragmentBomb returns n fragments, each spreading the next one twice.
+func fragmentBomb(n int) string {
+	var b strings.Builder
+	for i := 1; i < n; i++ {
+		b.WriteString("fragment f" + strconv.Itoa(i) + " on Query { a: name ...f" + strconv.Itoa(i+1) + " ...f" + strconv.Itoa(i+1) + " } ")
+	}
+	b.WriteString("fragment f" + strconv.Itoa(n) + " on Query { a: name }")
+	return b.String()
+}
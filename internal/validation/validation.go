@@ -44,6 +44,7 @@ type context struct {
 	overlapPairsObserved int
 	overlapLimitHit      bool
 	validateDeprecated   bool
+	skipVariableValues   bool
 }
 
 func (c *context) addErr(loc errors.Location, rule string, format string, a ...any) {
@@ -79,7 +80,37 @@ func newContext(s *ast.Schema, doc *ast.ExecutableDefinition, maxDepth int, over
 
 func Validate(s *ast.Schema, doc *ast.ExecutableDefinition, variables map[string]any, maxDepth int, overlapPairLimit int, validateDeprecated bool) []*errors.QueryError {
 	c := newContext(s, doc, maxDepth, overlapPairLimit, validateDeprecated)
+	return validate(c, variables)
+}
+
+// ValidateDocument performs all checks of [Validate] that do not depend on the variable values of a
+// request. Its result can be reused for every request with the same document, as long as the
+// variable values of each request are checked with [ValidateVariables].
+func ValidateDocument(s *ast.Schema, doc *ast.ExecutableDefinition, maxDepth int, overlapPairLimit int, validateDeprecated bool) []*errors.QueryError {
+	c := newContext(s, doc, maxDepth, overlapPairLimit, validateDeprecated)
+	c.skipVariableValues = true
+	return validate(c, nil)
+}
+
+// ValidateVariables checks the variable values of a request against the variable definitions of a
+// document that passed [ValidateDocument].
+func ValidateVariables(s *ast.Schema, doc *ast.ExecutableDefinition, variables map[string]any) []*errors.QueryError {
+	c := newContext(s, doc, 0, 0, false)
+	for _, op := range doc.Operations {
+		opc := &opContext{c, []*ast.OperationDefinition{op}}
+		for _, v := range op.Vars {
+			t, err := common.ResolveType(v.Type, s.Resolve)
+			if err != nil {
+				continue
+			}
+			validateValue(opc, v, variables[v.Name.Name], t)
+		}
+	}
+	return c.errs
+}
 
+func validate(c *context, variables map[string]any) []*errors.QueryError {
+	s, doc := c.schema, c.doc
 	opNames := make(nameSet, len(doc.Operations))
 	fragUsedBy := make(map[*ast.FragmentDefinition][]*ast.OperationDefinition)
 	for _, op := range doc.Operations {
@@ -110,7 +141,9 @@ func Validate(s *ast.Schema, doc *ast.ExecutableDefinition, variables map[string
 			if !canBeInput(t) {
 				c.addErr(v.TypeLoc, "VariablesAreInputTypesRule", "Variable %q cannot be non-input type %q.", "$"+v.Name.Name, t)
 			}
-			validateValue(opc, v, variables[v.Name.Name], t)
+			if !c.skipVariableValues {
+				validateValue(opc, v, variables[v.Name.Name], t)
+			}
 
 			if v.Default != nil {
 				validateLiteral(opc, v.Default)
@@ -684,6 +717,15 @@ func validateSelection(c *opContext, sel ast.Selection, t ast.NamedType) {
 			if !sf && sel.SelectionSet != nil {
 				c.addErr(sel.SelectionSetLoc, "ScalarLeafsRule", "Field %q must not have a selection since type %q has no subfields.", fieldName, ft)
 			}
+			if d := sel.Directives.Get("stream"); d != nil {
+				lt := ft
+				if nn, ok := lt.(*ast.NonNull); ok {
+					lt = nn.OfType
+				}
+				if _, ok := lt.(*ast.List); !ok {
+					c.addErr(d.Name.Loc, "StreamDirectiveOnListFieldRule", "Directive \"@stream\" cannot be used on non-list field %q.", fieldName)
+				}
+			}
 		}
 		if sel.SelectionSet != nil {
 			validateSelectionSet(c, sel.SelectionSet, unwrapType(ft))
@@ -99,6 +99,48 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+// TestValidateDocumentAndVariables verifies that splitting the validation into the
+// variable-independent and the variable-dependent part reports the same errors as Validate.
+func TestValidateDocumentAndVariables(t *testing.T) {
+	f, err := os.Open("testdata/tests.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var testData struct {
+		Schemas []Schema
+		Tests   []*Test
+	}
+	if err := json.NewDecoder(f).Decode(&testData); err != nil {
+		t.Fatal(err)
+	}
+
+	schemas := make(map[string]*ast.Schema, len(testData.Schemas))
+	for _, sc := range testData.Schemas {
+		s := schema.New()
+		if err := schema.Parse(s, sc.SDL, false); err != nil {
+			t.Fatal(err)
+		}
+		schemas[sc.ID] = s
+	}
+
+	for _, test := range testData.Tests {
+		d, err := query.Parse(test.Query)
+		if err != nil {
+			continue
+		}
+		s := schemas[test.Schema]
+		want := validation.Validate(s, d, test.Vars, 0, 0, false)
+		got := validation.ValidateDocument(s, d, 0, 0, false)
+		got = append(got, validation.ValidateVariables(s, d, test.Vars)...)
+		normalizeErrors(want)
+		normalizeErrors(got)
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: wrong errors\nexpected: %v\ngot:      %v", test.Name, want, got)
+		}
+	}
+}
+
 func normalizeErrors(errs []*errors.QueryError) {
 	for _, err := range errs {
 		locs := err.Locations
@@ -126,3 +168,39 @@ func normalizeErrors(errs []*errors.QueryError) {
 		return len(il) < len(jl)
 	})
 }
+
+func TestStreamDirectiveOnListField(t *testing.T) {
+	s := schema.New()
+	if err := schema.AddIncrementalDirectives(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Parse(s, `
+		type Query {
+			names: [String!]!
+			name: String
+		}
+	`, false); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{query: `{ names @stream(initialCount: 1) }`},
+		{query: `{ name @stream }`, want: `Directive "@stream" cannot be used on non-list field "name".`},
+	} {
+		d, err := query.Parse(tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errs := validation.Validate(s, d, nil, 0, 0, false)
+		var got string
+		if len(errs) > 0 {
+			got = errs[0].Message
+		}
+		if len(errs) > 1 || got != tc.want {
+			t.Errorf("%s: wrong errors\nexpected: %q\ngot:      %v", tc.query, tc.want, errs)
+		}
+	}
+}
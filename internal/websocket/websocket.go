@@ -0,0 +1,363 @@
+// Package websocket is a minimal server-side implementation of the WebSocket
+// protocol ([RFC 6455]) used by the subscription transports in the relay
+// package. It only supports what those transports need: the opening handshake,
+// text/binary messages (including fragmented ones), control frames and the
+// closing handshake. Extensions such as permessage-deflate are not negotiated.
+//
+// [RFC 6455]: https://www.rfc-editor.org/rfc/rfc6455
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // mandated by RFC 6455
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Opcodes defined by RFC 6455, section 5.2.
+const (
+	OpContinuation = 0x0
+	OpText         = 0x1
+	OpBinary       = 0x2
+	OpClose        = 0x8
+	OpPing         = 0x9
+	OpPong         = 0xA
+)
+
+// Close codes defined by RFC 6455, section 7.4.1.
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseNoStatus        = 1005
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+	defaultMaxMessageLen = 1 << 20 // 1MB
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrClosed is returned by [Conn.ReadMessage] and [Conn.WriteMessage] once the
+// connection has been closed.
+var ErrClosed = errors.New("websocket: connection closed")
+
+// CloseError is returned by [Conn.ReadMessage] when the peer sends a close frame.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: close %d %s", e.Code, e.Reason)
+}
+
+// Conn is a server-side WebSocket connection. ReadMessage must only be called
+// from a single goroutine, while WriteMessage and Close are safe for concurrent use.
+type Conn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	subprotocol string
+
+	// MaxMessageLen limits the size of a single (possibly fragmented) message.
+	MaxMessageLen int64
+
+	// WriteTimeout limits the time for writing a single frame, so that a peer which does not read
+	// cannot block writers indefinitely. A write that fails closes the connection. Zero means no
+	// timeout.
+	WriteTimeout time.Duration
+
+	wmu     sync.Mutex
+	closed  bool
+	closing atomic.Bool
+}
+
+// Upgrade performs the server side of the opening handshake and hijacks the
+// underlying connection. The first of the client's requested subprotocols that
+// is present in subprotocols is selected and can be read with [Conn.Subprotocol].
+// When the handshake fails an HTTP error response has already been written.
+func Upgrade(w http.ResponseWriter, r *http.Request, subprotocols []string) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "websocket: method not allowed", http.StatusMethodNotAllowed)
+		return nil, errors.New("websocket: method not allowed")
+	}
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "websocket: not a websocket handshake", http.StatusBadRequest)
+		return nil, errors.New("websocket: not a websocket handshake")
+	}
+	if r.Header.Get("Sec-Websocket-Version") != "13" {
+		w.Header().Set("Sec-Websocket-Version", "13")
+		http.Error(w, "websocket: unsupported version", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-Websocket-Key")
+	if key == "" {
+		http.Error(w, "websocket: missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("websocket: missing Sec-WebSocket-Key")
+	}
+
+	var selected string
+	for _, p := range headerTokens(r.Header, "Sec-Websocket-Protocol") {
+		for _, s := range subprotocols {
+			if p == s {
+				selected = s
+				break
+			}
+		}
+		if selected != "" {
+			break
+		}
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket: response does not implement http.Hijacker", http.StatusInternalServerError)
+		return nil, errors.New("websocket: response does not implement http.Hijacker")
+	}
+	nc, brw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	var resp strings.Builder
+	resp.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	resp.WriteString("Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n")
+	if selected != "" {
+		resp.WriteString("Sec-WebSocket-Protocol: " + selected + "\r\n")
+	}
+	resp.WriteString("\r\n")
+	if _, err := nc.Write([]byte(resp.String())); err != nil {
+		nc.Close()
+		return nil, err
+	}
+
+	return &Conn{
+		conn:          nc,
+		br:            brw.Reader,
+		subprotocol:   selected,
+		MaxMessageLen: defaultMaxMessageLen,
+	}, nil
+}
+
+// AcceptKey computes the Sec-WebSocket-Accept value for the given client key.
+func AcceptKey(key string) string {
+	h := sha1.New() //nolint:gosec // mandated by RFC 6455
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Subprotocol returns the negotiated subprotocol or an empty string.
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// ReadMessage reads the next data message. Ping frames are answered
+// automatically and pong frames are ignored. When the peer starts the closing
+// handshake the close frame is echoed and a [*CloseError] is returned.
+func (c *Conn) ReadMessage() (opcode int, data []byte, err error) {
+	var msg []byte
+	msgOp := -1
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch op {
+		case OpPing:
+			if err := c.writeFrame(OpPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case OpPong:
+			continue
+		case OpClose:
+			ce := &CloseError{Code: CloseNoStatus}
+			if len(payload) >= 2 {
+				ce.Code = int(binary.BigEndian.Uint16(payload))
+				ce.Reason = string(payload[2:])
+			}
+			_ = c.Close(ce.Code, "")
+			return 0, nil, ce
+		case OpText, OpBinary:
+			if msgOp != -1 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected data frame inside fragmented message")
+			}
+			msgOp = op
+		case OpContinuation:
+			if msgOp == -1 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return 0, nil, c.fail(CloseProtocolError, fmt.Sprintf("unknown opcode %d", op))
+		}
+
+		if int64(len(msg)+len(payload)) > c.MaxMessageLen {
+			return 0, nil, c.fail(CloseMessageTooBig, "message too big")
+		}
+		msg = append(msg, payload...)
+		if fin {
+			return msgOp, msg, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.br, hdr[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = hdr[0]&0x80 != 0
+	if hdr[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "reserved bits set")
+	}
+	opcode = int(hdr[0] & 0x0f)
+	masked := hdr[1]&0x80 != 0
+	if !masked {
+		return false, 0, nil, c.fail(CloseProtocolError, "client frames must be masked")
+	}
+
+	n := int64(hdr[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+	if opcode >= OpClose && (n > 125 || !fin) {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+	}
+	if n < 0 || n > c.MaxMessageLen {
+		return false, 0, nil, c.fail(CloseMessageTooBig, "message too big")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// WriteMessage writes a single unfragmented data message.
+func (c *Conn) WriteMessage(opcode int, data []byte) error {
+	return c.writeFrame(opcode, data)
+}
+
+// SetReadDeadline sets the deadline for future reads on the underlying connection.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *Conn) writeFrame(opcode int, data []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closed || c.closing.Load() {
+		return ErrClosed
+	}
+	if c.WriteTimeout > 0 {
+		_ = c.conn.SetWriteDeadline(time.Now().Add(c.WriteTimeout))
+	}
+	if err := writeFrame(c.conn, opcode, data); err != nil {
+		// The frame may have been written partially, so the connection can not be used anymore.
+		c.closed = true
+		c.conn.Close()
+		return err
+	}
+	return nil
+}
+
+func writeFrame(w io.Writer, opcode int, data []byte) error {
+	hdr := make([]byte, 2, 10)
+	hdr[0] = 0x80 | byte(opcode)
+	switch n := len(data); {
+	case n <= 125:
+		hdr[1] = byte(n)
+	case n <= 0xffff:
+		hdr[1] = 126
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(n))
+	default:
+		hdr[1] = 127
+		hdr = binary.BigEndian.AppendUint64(hdr, uint64(n))
+	}
+	if _, err := w.Write(append(hdr, data...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Close sends a close frame with the given code and reason and closes the
+// underlying connection. A pending write is interrupted first, in which case the
+// connection is closed without a close frame. Calling Close more than once is a no-op.
+func (c *Conn) Close(code int, reason string) error {
+	// A write to a peer that does not read holds the lock until it times out, so it is cancelled
+	// by an expired deadline. Writes which have not started yet are rejected.
+	c.closing.Store(true)
+	_ = c.conn.SetWriteDeadline(time.Now())
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	var payload []byte
+	if code != CloseNoStatus {
+		if len(reason) > 123 {
+			reason = reason[:123]
+		}
+		payload = binary.BigEndian.AppendUint16(nil, uint16(code))
+		payload = append(payload, reason...)
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+	_ = writeFrame(c.conn, OpClose, payload)
+	return c.conn.Close()
+}
+
+func (c *Conn) fail(code int, reason string) error {
+	_ = c.Close(code, reason)
+	return &CloseError{Code: code, Reason: reason}
+}
+
+func headerContains(h http.Header, name, token string) bool {
+	for _, t := range headerTokens(h, name) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
+
+func headerTokens(h http.Header, name string) []string {
+	var tokens []string
+	for _, v := range h.Values(name) {
+		for t := range strings.SplitSeq(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tokens = append(tokens, t)
+			}
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,86 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455, section 1.3.
+	if got, want := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="; got != want {
+		t.Fatalf("AcceptKey() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteFrameLengths(t *testing.T) {
+	for _, tc := range []struct {
+		n      int
+		header []byte
+	}{
+		{n: 5, header: []byte{0x81, 5}},
+		{n: 126, header: []byte{0x81, 126, 0, 126}},
+		{n: 70000, header: []byte{0x81, 127, 0, 0, 0, 0, 0, 1, 0x11, 0x70}},
+	} {
+		var buf bytes.Buffer
+		if err := writeFrame(&buf, OpText, make([]byte, tc.n)); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.Bytes()[:len(tc.header)]; !bytes.Equal(got, tc.header) {
+			t.Errorf("payload of %d bytes: header = %v, want %v", tc.n, got, tc.header)
+		}
+		if buf.Len() != len(tc.header)+tc.n {
+			t.Errorf("payload of %d bytes: frame length = %d, want %d", tc.n, buf.Len(), len(tc.header)+tc.n)
+		}
+	}
+}
+
+// pipeConn returns a connection whose peer never reads, so that writes block.
+func pipeConn(t *testing.T) *Conn {
+	t.Helper()
+	server, client := net.Pipe()
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+	return &Conn{conn: server, br: bufio.NewReader(server), MaxMessageLen: defaultMaxMessageLen}
+}
+
+func TestWriteTimeout(t *testing.T) {
+	c := pipeConn(t)
+	c.WriteTimeout = 10 * time.Millisecond
+
+	var ne net.Error
+	if err := c.WriteMessage(OpText, []byte("hello")); !errors.As(err, &ne) || !ne.Timeout() {
+		t.Fatalf("WriteMessage() = %v, want timeout", err)
+	}
+	if err := c.WriteMessage(OpText, []byte("hello")); err != ErrClosed {
+		t.Fatalf("WriteMessage() after timeout = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestCloseInterruptsWrite(t *testing.T) {
+	c := pipeConn(t)
+
+	written := make(chan error, 1)
+	go func() {
+		written <- c.WriteMessage(OpText, []byte("hello"))
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- c.Close(CloseNormal, "")
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close blocked by a pending write")
+	}
+	if err := <-written; err == nil {
+		t.Fatal("pending write succeeded after Close")
+	}
+}
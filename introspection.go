@@ -4,8 +4,13 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/graph-gophers/graphql-go/internal/exec"
 	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+	"github.com/graph-gophers/graphql-go/internal/exec/selected"
+	"github.com/graph-gophers/graphql-go/internal/query"
+	"github.com/graph-gophers/graphql-go/internal/validation"
 	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace/noop"
 )
 
 // Inspect allows inspection of the given schema.
@@ -14,16 +19,41 @@ func (s *Schema) Inspect() *introspection.Schema {
 }
 
 // ToJSON encodes the schema in a JSON format used by tools like Relay.
+//
+// The introspection query is executed without the limits, caches, plugins and tracers that apply to
+// requests.
 func (s *Schema) ToJSON() ([]byte, error) {
-	result := s.exec(context.Background(), introspectionQuery, "", nil, &resolvable.Schema{
+	doc, qErr := query.Parse(introspectionQuery)
+	if qErr != nil {
+		return nil, qErr
+	}
+	if errs := validation.Validate(s.schema, doc, nil, 0, 0, false); len(errs) != 0 {
+		return nil, errs[0]
+	}
+	r := &exec.Request{
+		Request: selected.Request{
+			Doc:                doc,
+			Vars:               map[string]any{},
+			Schema:             s.schema,
+			AllowIntrospection: true,
+		},
+		Limiter:                 make(chan struct{}, s.maxParallelism),
+		Tracer:                  noop.Tracer{},
+		Logger:                  s.logger,
+		PanicHandler:            s.panicHandler,
+		DisableMemoryPooling:    s.disableMemoryPooling,
+		MaxPooledBufferCapacity: s.maxPooledBufferCapacity,
+	}
+	res := &resolvable.Schema{
 		Meta:   s.res.Meta,
 		Query:  &resolvable.Object{},
 		Schema: *s.schema,
-	})
-	if len(result.Errors) != 0 {
-		panic(result.Errors[0])
 	}
-	return json.MarshalIndent(result.Data, "", "\t")
+	data, errs := r.Execute(context.Background(), res, doc.Operations[0])
+	if len(errs) != 0 {
+		return nil, errs[0]
+	}
+	return json.MarshalIndent(json.RawMessage(data), "", "\t")
 }
 
 var introspectionQuery = `
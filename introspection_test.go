@@ -35,6 +35,19 @@ func TestSchema_ToJSON(t *testing.T) {
 			Args: args{Schema: graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})},
 			Want: want{JSON: mustReadFile("example/starwars/introspect.json")},
 		},
+		{
+			// The introspection query of ToJSON is not subject to the limits and plugins of requests.
+			Name: "Star Wars Schema with strict limits",
+			Args: args{Schema: graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{},
+				graphql.MaxTokens(50),
+				graphql.MaxFragments(2),
+				graphql.MaxDepth(2),
+				graphql.MaxQueryLength(100),
+				graphql.DocumentCache(1),
+				graphql.UsePlugins(policyPlugin{}),
+			)},
+			Want: want{JSON: mustReadFile("example/starwars/introspect.json")},
+		},
 		{
 			Name: "Star Wars Schema without Resolver",
 			Args: args{Schema: graphql.MustParseSchema(starwars.Schema, nil)},
@@ -0,0 +1,74 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type limitsResolver struct{}
+
+func (limitsResolver) Hello() string { return "world" }
+
+func TestOperationLimits(t *testing.T) {
+	const sdl = `type Query { hello: String! }`
+
+	for _, tc := range []struct {
+		name  string
+		opt   graphql.SchemaOpt
+		query string
+		want  string
+	}{
+		{
+			name:  "within limits",
+			opt:   graphql.MaxAliases(1),
+			query: `{ a: hello hello }`,
+			want:  `{"data":{"a":"world","hello":"world"}}`,
+		},
+		{
+			name:  "aliases",
+			opt:   graphql.MaxAliases(1),
+			query: `{ a: hello b: hello }`,
+			want:  `{"errors":[{"message":"Operation exceeds the maximum of 1 aliases.","locations":[{"line":1,"column":1}],"extensions":{"code":"MAX_ALIASES_EXCEEDED"}}]}`,
+		},
+		{
+			name:  "root fields",
+			opt:   graphql.MaxRootFields(2),
+			query: `query { a: hello b: hello c: hello }`,
+			want:  `{"errors":[{"message":"Operation exceeds the maximum of 2 root fields.","locations":[{"line":1,"column":1}],"extensions":{"code":"MAX_ROOT_FIELDS_EXCEEDED"}}]}`,
+		},
+		{
+			name:  "tokens",
+			opt:   graphql.MaxTokens(5),
+			query: `{ a: hello b: hello }`,
+			want:  `{"errors":[{"message":"Document exceeds the maximum of 5 tokens.","locations":[{"line":1,"column":13}],"extensions":{"code":"MAX_TOKENS_EXCEEDED"}}]}`,
+		},
+		{
+			name:  "directives per field",
+			opt:   graphql.MaxDirectivesPerField(1),
+			query: `{ hello @skip(if: false) @include(if: true) }`,
+			want:  `{"errors":[{"message":"Field \"hello\" has 2 directives that exceeds max directives per field 1","locations":[{"line":1,"column":3}],"extensions":{"code":"MAX_DIRECTIVES_PER_FIELD_EXCEEDED"}}]}`,
+		},
+		{
+			name:  "fragments",
+			opt:   graphql.MaxFragments(1),
+			query: `{ ...f ... on Query { hello } } fragment f on Query { hello }`,
+			want:  `{"errors":[{"message":"Document exceeds the maximum of 1 fragments.","locations":[{"line":1,"column":8}],"extensions":{"code":"MAX_FRAGMENTS_EXCEEDED"}}]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			schema := graphql.MustParseSchema(sdl, &limitsResolver{}, tc.opt)
+			if got := responseJSON(t, schema.Exec(context.Background(), tc.query, "", nil)); got != tc.want {
+				t.Errorf("wrong response\nwant: %s\ngot:  %s", tc.want, got)
+			}
+			// Documents exceeding the limits are also rejected when cached.
+			schema = graphql.MustParseSchema(sdl, &limitsResolver{}, tc.opt, graphql.DocumentCache(10))
+			for range 2 {
+				if got := responseJSON(t, schema.Exec(context.Background(), tc.query, "", nil)); got != tc.want {
+					t.Errorf("wrong cached response\nwant: %s\ngot:  %s", tc.want, got)
+				}
+			}
+		})
+	}
+}
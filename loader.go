@@ -0,0 +1,153 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go/internal/exec"
+)
+
+// BatchFunc loads the values of a batch of keys. The values must be returned in the order of the
+// keys. The returned errors are either nil, a single error which applies to all keys, or one error
+// per key. The values may be nil if errors are returned.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) ([]V, []error)
+
+// Loader batches and caches the loading of values by key, which avoids N+1 queries when resolving
+// lists. A Loader is typically declared once and shared by all requests, while the loaded values
+// are cached per request.
+//
+// Resolvers call [Loader.Load] and return the resulting thunk, a func() (V, error), instead of the
+// value itself. The executor calls the resolvers of all sibling fields, including the fields of all
+// items of a list, before calling the first thunk, which loads all keys collected so far in a
+// single call of Fetch. Fields with field middleware, directive handlers or a timeout are excluded,
+// since their resolvers are only called once the wrappers call next:
+//
+//	var userLoader = &graphql.Loader[string, *User]{Name: "users", Fetch: fetchUsers}
+//
+//	func (p *postResolver) Author(ctx context.Context) func() (*userResolver, error) {
+//		load := userLoader.Load(ctx, p.authorID)
+//		return func() (*userResolver, error) {
+//			u, err := load()
+//			return &userResolver{u}, err
+//		}
+//	}
+//
+// Each batch is traced with a single span by tracers implementing tracer.BatchTracer.
+type Loader[K comparable, V any] struct {
+	// Name identifies the loader in traces and errors.
+	Name string
+
+	// Fetch loads the values of a batch of keys.
+	Fetch BatchFunc[K, V]
+
+	// MaxBatchSize limits the number of keys loaded by one call of Fetch. The default is 0 which
+	// disables the limit.
+	MaxBatchSize int
+}
+
+// Load returns a thunk which returns the value of the key. The value is loaded when the first
+// thunk of the batch containing the key is called. Keys loaded before in the same request are
+// served from the cache of the request.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (V, error) {
+	var st *loaderState[K, V]
+	if loaders := exec.LoadersFromContext(ctx); loaders != nil {
+		st = loaders.State(l, func() any { return &loaderState[K, V]{loader: l, loaders: loaders} }).(*loaderState[K, V])
+	} else {
+		// Without a request, loads are neither batched nor cached.
+		st = &loaderState[K, V]{loader: l}
+	}
+	return st.load(ctx, key)
+}
+
+// loaderState is the state of a loader in a request.
+type loaderState[K comparable, V any] struct {
+	loader  *Loader[K, V]
+	loaders *exec.Loaders
+
+	mu      sync.Mutex
+	cache   map[K]func() (V, error)
+	pending *loadBatch[K, V]
+}
+
+// loadBatch is a batch of keys which are loaded with one call of Fetch.
+type loadBatch[K comparable, V any] struct {
+	keys   []K
+	once   sync.Once
+	values []V
+	errs   []error
+}
+
+func (st *loaderState[K, V]) load(ctx context.Context, key K) func() (V, error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if thunk, ok := st.cache[key]; ok {
+		return thunk
+	}
+
+	b := st.pending
+	if b == nil || (st.loader.MaxBatchSize > 0 && len(b.keys) >= st.loader.MaxBatchSize) {
+		b = &loadBatch[K, V]{}
+		st.pending = b
+	}
+	i := len(b.keys)
+	b.keys = append(b.keys, key)
+
+	thunk := func() (V, error) {
+		b.once.Do(func() { st.fetch(ctx, b) })
+		var v V
+		if i < len(b.values) {
+			v = b.values[i]
+		}
+		switch len(b.errs) {
+		case 0:
+			return v, nil
+		case 1:
+			return v, b.errs[0]
+		default:
+			return v, b.errs[i]
+		}
+	}
+	if st.cache == nil {
+		st.cache = make(map[K]func() (V, error))
+	}
+	st.cache[key] = thunk
+	return thunk
+}
+
+func (st *loaderState[K, V]) fetch(ctx context.Context, b *loadBatch[K, V]) {
+	st.mu.Lock()
+	if st.pending == b {
+		st.pending = nil
+	}
+	st.mu.Unlock()
+
+	finish := func(error) {}
+	if st.loaders != nil {
+		ctx, finish = st.loaders.TraceBatch(ctx, st.loader.Name, len(b.keys))
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			b.values, b.errs = nil, []error{fmt.Errorf("graphql: panic occurred in loader %q: %v", st.loader.Name, v)}
+		}
+		finish(batchError(b.errs))
+	}()
+
+	b.values, b.errs = st.loader.Fetch(ctx, b.keys)
+	switch {
+	case len(b.values) != len(b.keys) && (b.values != nil || len(b.errs) == 0):
+		b.values, b.errs = nil, []error{fmt.Errorf("graphql: loader %q returned %d values for %d keys", st.loader.Name, len(b.values), len(b.keys))}
+	case len(b.errs) > 1 && len(b.errs) != len(b.keys):
+		b.values, b.errs = nil, []error{fmt.Errorf("graphql: loader %q returned %d errors for %d keys", st.loader.Name, len(b.errs), len(b.keys))}
+	}
+}
+
+// batchError returns the first error of a batch.
+func batchError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
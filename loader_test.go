@@ -0,0 +1,232 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace/noop"
+)
+
+const loaderSchema = `
+	type Query {
+		posts: [Post!]!
+		user(id: ID!): User
+	}
+	type Post {
+		title: String!
+		author: User
+	}
+	type User {
+		id: ID!
+		name: String!
+	}
+`
+
+type loaderUser struct {
+	id   string
+	name string
+}
+
+func (u *loaderUser) ID() graphql.ID { return graphql.ID(u.id) }
+func (u *loaderUser) Name() string   { return u.name }
+
+type loaderResolver struct {
+	users *graphql.Loader[string, *loaderUser]
+	posts []*loaderPost
+}
+
+func (r *loaderResolver) Posts() []*loaderPost {
+	for _, p := range r.posts {
+		p.users = r.users
+	}
+	return r.posts
+}
+
+func (r *loaderResolver) User(ctx context.Context, args struct{ ID graphql.ID }) func() (*loaderUser, error) {
+	return r.users.Load(ctx, string(args.ID))
+}
+
+type loaderPost struct {
+	title    string
+	authorID string
+	users    *graphql.Loader[string, *loaderUser]
+}
+
+func (p *loaderPost) Title() string { return p.title }
+
+func (p *loaderPost) Author(ctx context.Context) func() (*loaderUser, error) {
+	return p.users.Load(ctx, p.authorID)
+}
+
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (b *batchRecorder) fetch(_ context.Context, keys []string) ([]*loaderUser, []error) {
+	b.mu.Lock()
+	b.batches = append(b.batches, keys)
+	b.mu.Unlock()
+
+	users := make([]*loaderUser, len(keys))
+	errs := make([]error, len(keys))
+	for i, k := range keys {
+		if k == "missing" {
+			errs[i] = errors.New("user not found")
+			continue
+		}
+		users[i] = &loaderUser{id: k, name: "user " + k}
+	}
+	return users, errs
+}
+
+func TestLoader(t *testing.T) {
+	posts := []*loaderPost{
+		{title: "a", authorID: "1"},
+		{title: "b", authorID: "2"},
+		{title: "c", authorID: "1"},
+		{title: "d", authorID: "3"},
+		{title: "e", authorID: "missing"},
+	}
+
+	for _, tc := range []struct {
+		name         string
+		maxBatchSize int
+		query        string
+		want         string
+		batches      [][]string
+	}{
+		{
+			name:    "list",
+			query:   `{ posts { title author { name } } }`,
+			want:    `{"errors":[{"message":"user not found","locations":[{"line":1,"column":17}],"path":["posts",4,"author"]}],"data":{"posts":[{"title":"a","author":{"name":"user 1"}},{"title":"b","author":{"name":"user 2"}},{"title":"c","author":{"name":"user 1"}},{"title":"d","author":{"name":"user 3"}},{"title":"e","author":null}]}}`,
+			batches: [][]string{{"1", "2", "3", "missing"}},
+		},
+		{
+			name:         "max batch size",
+			maxBatchSize: 2,
+			query:        `{ posts { author { id } } }`,
+			want:         `{"errors":[{"message":"user not found","locations":[{"line":1,"column":11}],"path":["posts",4,"author"]}],"data":{"posts":[{"author":{"id":"1"}},{"author":{"id":"2"}},{"author":{"id":"1"}},{"author":{"id":"3"}},{"author":null}]}}`,
+			batches:      [][]string{{"1", "2"}, {"3", "missing"}},
+		},
+		{
+			name:    "sibling fields",
+			query:   `{ a: user(id: "1") { name } b: user(id: "2") { name } c: user(id: "1") { id } }`,
+			want:    `{"data":{"a":{"name":"user 1"},"b":{"name":"user 2"},"c":{"id":"1"}}}`,
+			batches: [][]string{{"1", "2"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := &batchRecorder{}
+			users := &graphql.Loader[string, *loaderUser]{Name: "users", Fetch: rec.fetch, MaxBatchSize: tc.maxBatchSize}
+			schema := graphql.MustParseSchema(loaderSchema, &loaderResolver{users: users, posts: posts})
+
+			// Values are only cached per request.
+			for range 2 {
+				rec.batches = nil
+				if got := responseJSON(t, schema.Exec(context.Background(), tc.query, "", nil)); got != tc.want {
+					t.Errorf("wrong response\nwant: %s\ngot:  %s", tc.want, got)
+				}
+				// Batches limited by MaxBatchSize are loaded concurrently.
+				sort.Slice(rec.batches, func(i, j int) bool { return rec.batches[i][0] < rec.batches[j][0] })
+				if !reflect.DeepEqual(rec.batches, tc.batches) {
+					t.Errorf("wrong batches\nwant: %v\ngot:  %v", tc.batches, rec.batches)
+				}
+			}
+		})
+	}
+}
+
+func TestLoaderErrors(t *testing.T) {
+	posts := []*loaderPost{{title: "a", authorID: "1"}}
+
+	for _, tc := range []struct {
+		name  string
+		fetch graphql.BatchFunc[string, *loaderUser]
+		want  string
+	}{
+		{
+			name: "batch error",
+			fetch: func(_ context.Context, keys []string) ([]*loaderUser, []error) {
+				return nil, []error{errors.New("database down")}
+			},
+			want: `{"errors":[{"message":"database down","locations":[{"line":1,"column":11}],"path":["posts",0,"author"]}],"data":{"posts":[{"author":null}]}}`,
+		},
+		{
+			name: "wrong number of values",
+			fetch: func(_ context.Context, keys []string) ([]*loaderUser, []error) {
+				return []*loaderUser{{id: "1"}, {id: "2"}}, nil
+			},
+			want: `{"errors":[{"message":"graphql: loader \"users\" returned 2 values for 1 keys","locations":[{"line":1,"column":11}],"path":["posts",0,"author"]}],"data":{"posts":[{"author":null}]}}`,
+		},
+		{
+			name: "panic",
+			fetch: func(_ context.Context, keys []string) ([]*loaderUser, []error) {
+				panic("boom")
+			},
+			want: `{"errors":[{"message":"graphql: panic occurred in loader \"users\": boom","locations":[{"line":1,"column":11}],"path":["posts",0,"author"]}],"data":{"posts":[{"author":null}]}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			users := &graphql.Loader[string, *loaderUser]{Name: "users", Fetch: tc.fetch}
+			schema := graphql.MustParseSchema(loaderSchema, &loaderResolver{users: users, posts: posts})
+			if got := responseJSON(t, schema.Exec(context.Background(), `{ posts { author { id } } }`, "", nil)); got != tc.want {
+				t.Errorf("wrong response\nwant: %s\ngot:  %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLoaderWithoutRequest(t *testing.T) {
+	rec := &batchRecorder{}
+	users := &graphql.Loader[string, *loaderUser]{Name: "users", Fetch: rec.fetch}
+	a, b := users.Load(context.Background(), "1"), users.Load(context.Background(), "1")
+	if u, err := a(); err != nil || u.name != "user 1" {
+		t.Errorf("unexpected result %v, %v", u, err)
+	}
+	if u, err := b(); err != nil || u.name != "user 1" {
+		t.Errorf("unexpected result %v, %v", u, err)
+	}
+	if want := [][]string{{"1"}, {"1"}}; !reflect.DeepEqual(rec.batches, want) {
+		t.Errorf("wrong batches\nwant: %v\ngot:  %v", want, rec.batches)
+	}
+}
+
+type batchTracer struct {
+	noop.Tracer
+	mu    sync.Mutex
+	spans []string
+}
+
+func (t *batchTracer) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]any, varTypes map[string]*introspection.Type) (context.Context, func([]*qerrors.QueryError)) {
+	return ctx, func([]*qerrors.QueryError) {}
+}
+
+func (t *batchTracer) TraceBatch(ctx context.Context, loader string, keys int) (context.Context, func(error)) {
+	return ctx, func(err error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.spans = append(t.spans, fmt.Sprintf("%s: %d keys, error: %v", loader, keys, err))
+	}
+}
+
+func TestLoaderTracing(t *testing.T) {
+	posts := []*loaderPost{{title: "a", authorID: "1"}, {title: "b", authorID: "2"}, {title: "c", authorID: "missing"}}
+	rec := &batchRecorder{}
+	users := &graphql.Loader[string, *loaderUser]{Name: "users", Fetch: rec.fetch}
+	tr := &batchTracer{}
+	schema := graphql.MustParseSchema(loaderSchema, &loaderResolver{users: users, posts: posts}, graphql.Tracer(tr))
+
+	schema.Exec(context.Background(), `{ posts { author { id } } }`, "", nil)
+	if want := []string{"users: 3 keys, error: user not found"}; !reflect.DeepEqual(tr.spans, want) {
+		t.Errorf("wrong spans\nwant: %v\ngot:  %v", want, tr.spans)
+	}
+}
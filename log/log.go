@@ -11,6 +11,13 @@ type Logger interface {
 	LogPanic(ctx context.Context, value any)
 }
 
+// MessageLogger is implemented by loggers which also log messages other than panics, such as the
+// reports of untrusted documents. Loggers which do not implement it leave these messages to
+// [DefaultLogger].
+type MessageLogger interface {
+	Logf(ctx context.Context, format string, args ...any)
+}
+
 // LoggerFunc is a function type that implements the Logger interface.
 type LoggerFunc func(ctx context.Context, value any)
 
@@ -29,3 +36,8 @@ func (l *DefaultLogger) LogPanic(ctx context.Context, value any) {
 	buf = buf[:runtime.Stack(buf, false)]
 	log.Printf("graphql: panic occurred: %v\n%s\ncontext: %v", value, buf, ctx)
 }
+
+// Logf logs a message with the standard library logger.
+func (l *DefaultLogger) Logf(_ context.Context, format string, args ...any) {
+	log.Printf(format, args...)
+}
@@ -0,0 +1,89 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/internal/exec"
+	"github.com/graph-gophers/graphql-go/internal/exec/selected"
+)
+
+// FieldInfo describes a field being resolved.
+type FieldInfo struct {
+	// TypeName is the name of the type the field belongs to.
+	TypeName string
+	// FieldName is the name of the field in the schema.
+	FieldName string
+	// Alias is the name of the field in the response, which is the field name if the query does
+	// not use an alias.
+	Alias string
+	// Args holds the coerced arguments of the field. It must not be modified.
+	Args map[string]any
+	// Path is the path of the field in the response.
+	Path []any
+	// Trivial is true if the field is resolved by a struct field instead of a method.
+	Trivial bool
+	// Definition is the definition of the field in the schema. It must not be modified.
+	Definition *ast.FieldDefinition
+}
+
+// Resolve resolves a field and returns its value.
+type Resolve func(ctx context.Context) (any, error)
+
+// FieldMiddleware wraps the resolution of fields. It may call next, possibly with a modified
+// context, and return its result, replace the result, or return an error without calling next.
+// A nil result resolves the field to null. A replaced result must have the type returned by the
+// resolver of the field. Errors are reported like resolver errors.
+//
+// If the resolver of the field returns a thunk (see [Loader]), next returns the value of the thunk.
+// The resolver is only called by next, so that middleware may prevent it from running. Therefore
+// the resolvers of fields with middleware are not called ahead of their siblings, and the keys they
+// load are not batched with the keys of the other items of a list.
+type FieldMiddleware func(ctx context.Context, info FieldInfo, next Resolve) (any, error)
+
+// UseFieldMiddleware adds middleware which is invoked for the resolution of every field except
+// the root field of subscriptions. Middleware added first is invoked first.
+func UseFieldMiddleware(mw ...FieldMiddleware) SchemaOpt {
+	return func(s *Schema) {
+		s.fieldMiddleware = append(s.fieldMiddleware[:len(s.fieldMiddleware):len(s.fieldMiddleware)], mw...)
+	}
+}
+
+// SkipTrivialFieldMiddleware does not invoke the field middleware for fields resolved by struct
+// fields (see [UseFieldResolvers]), which avoids its overhead for fields without resolver logic.
+func SkipTrivialFieldMiddleware() SchemaOpt {
+	return func(s *Schema) {
+		s.skipTrivialMiddleware = true
+	}
+}
+
+// execFieldMiddleware returns the field middleware of the schema for the executor.
+func (s *Schema) execFieldMiddleware() exec.FieldMiddleware {
+	mws := s.fieldMiddleware
+	if len(mws) == 0 {
+		return nil
+	}
+	return func(ctx context.Context, f *selected.SchemaField, path []any, next func(context.Context) (any, error)) (any, error) {
+		info := newFieldInfo(f, path)
+		h := Resolve(next)
+		for i := len(mws) - 1; i >= 0; i-- {
+			mw, next := mws[i], h
+			h = func(ctx context.Context) (any, error) {
+				return mw(ctx, info, next)
+			}
+		}
+		return h(ctx)
+	}
+}
+
+func newFieldInfo(f *selected.SchemaField, path []any) FieldInfo {
+	return FieldInfo{
+		TypeName:   f.TypeName,
+		FieldName:  f.Name,
+		Alias:      f.Alias,
+		Args:       f.Args,
+		Path:       path,
+		Trivial:    !f.UseMethodResolver(),
+		Definition: &f.FieldDefinition,
+	}
+}
@@ -0,0 +1,169 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const middlewareSchema = `
+	type Query {
+		user(id: ID!): User
+		users: [User!]!
+	}
+	type User {
+		id: ID!
+		name: String!
+		email: String
+	}
+`
+
+type middlewareResolver struct{}
+
+func (middlewareResolver) User(args struct{ ID graphql.ID }) *middlewareUser {
+	return &middlewareUser{ID: args.ID, Name: "user " + string(args.ID)}
+}
+
+func (middlewareResolver) Users() []*middlewareUser {
+	return []*middlewareUser{{ID: "1", Name: "user 1"}, {ID: "2", Name: "user 2"}}
+}
+
+type middlewareUser struct {
+	ID   graphql.ID
+	Name string
+}
+
+func (u *middlewareUser) Email() *string {
+	email := "user" + string(u.ID) + "@example.com"
+	return &email
+}
+
+func TestFieldMiddleware(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		opts  []graphql.SchemaOpt
+		query string
+		want  string
+	}{
+		{
+			name: "short-circuit",
+			opts: []graphql.SchemaOpt{graphql.UseFieldMiddleware(func(ctx context.Context, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+				if info.TypeName == "User" && info.FieldName == "email" {
+					return nil, errors.New("forbidden")
+				}
+				return next(ctx)
+			})},
+			query: `{ user(id: "1") { name email } }`,
+			want:  `{"errors":[{"message":"forbidden","locations":[{"line":1,"column":24}],"path":["user","email"]}],"data":{"user":{"name":"user 1","email":null}}}`,
+		},
+		{
+			name: "replace result",
+			opts: []graphql.SchemaOpt{graphql.UseFieldMiddleware(func(ctx context.Context, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+				v, err := next(ctx)
+				if s, ok := v.(string); ok {
+					return strings.ToUpper(s), err
+				}
+				return v, err
+			})},
+			query: `{ users { name } }`,
+			want:  `{"data":{"users":[{"name":"USER 1"},{"name":"USER 2"}]}}`,
+		},
+		{
+			name: "wrong type",
+			opts: []graphql.SchemaOpt{graphql.UseFieldMiddleware(func(ctx context.Context, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+				if info.FieldName == "name" {
+					return 42, nil
+				}
+				return next(ctx)
+			})},
+			query: `{ user(id: "1") { id name } }`,
+			want:  `{"errors":[{"message":"graphql: field middleware returned int for field User.name, expected string","locations":[{"line":1,"column":22}],"path":["user","name"]}],"data":{"user":null}}`,
+		},
+		{
+			name: "skip trivial",
+			opts: []graphql.SchemaOpt{
+				graphql.SkipTrivialFieldMiddleware(),
+				graphql.UseFieldMiddleware(func(ctx context.Context, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+					if info.TypeName == "User" {
+						return nil, fmt.Errorf("%s is not trivial", info.FieldName)
+					}
+					return next(ctx)
+				}),
+			},
+			query: `{ user(id: "1") { id name email } }`,
+			want:  `{"errors":[{"message":"email is not trivial","locations":[{"line":1,"column":27}],"path":["user","email"]}],"data":{"user":{"id":"1","name":"user 1","email":null}}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append([]graphql.SchemaOpt{graphql.UseFieldResolvers()}, tc.opts...)
+			schema := graphql.MustParseSchema(middlewareSchema, &middlewareResolver{}, opts...)
+			if got := responseJSON(t, schema.Exec(context.Background(), tc.query, "", nil)); got != tc.want {
+				t.Errorf("wrong response\nwant: %s\ngot:  %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFieldMiddlewareOrder(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) graphql.FieldMiddleware {
+		return func(ctx context.Context, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+			mu.Lock()
+			calls = append(calls, name+" "+info.FieldName)
+			mu.Unlock()
+			return next(ctx)
+		}
+	}
+	schema := graphql.MustParseSchema(middlewareSchema, &middlewareResolver{},
+		graphql.UseFieldResolvers(),
+		graphql.UseFieldMiddleware(record("a"), record("b")),
+		graphql.UseFieldMiddleware(record("c")),
+	)
+
+	want := `{"data":{"user":{"id":"1"}}}`
+	if got := responseJSON(t, schema.Exec(context.Background(), `{ user(id: "1") { id } }`, "", nil)); got != want {
+		t.Errorf("wrong response\nwant: %s\ngot:  %s", want, got)
+	}
+	wantCalls := []string{"a user", "b user", "c user", "a id", "b id", "c id"}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("wrong calls\nwant: %v\ngot:  %v", wantCalls, calls)
+	}
+}
+
+func TestFieldMiddlewareInfo(t *testing.T) {
+	var mu sync.Mutex
+	infos := make(map[string]graphql.FieldInfo)
+	schema := graphql.MustParseSchema(middlewareSchema, &middlewareResolver{},
+		graphql.UseFieldResolvers(),
+		graphql.UseFieldMiddleware(func(ctx context.Context, info graphql.FieldInfo, next graphql.Resolve) (any, error) {
+			if info.Definition == nil || info.Definition.Name != info.FieldName {
+				t.Errorf("wrong definition %v for field %s", info.Definition, info.FieldName)
+			}
+			info.Definition = nil
+			mu.Lock()
+			infos[fmt.Sprint(info.Path)] = info
+			mu.Unlock()
+			return next(ctx)
+		}),
+	)
+
+	want := `{"data":{"u":{"name":"user 1","email":"user1@example.com"}}}`
+	if got := responseJSON(t, schema.Exec(context.Background(), `{ u: user(id: "1") { name email } }`, "", nil)); got != want {
+		t.Errorf("wrong response\nwant: %s\ngot:  %s", want, got)
+	}
+	wantInfos := map[string]graphql.FieldInfo{
+		"[u]":       {TypeName: "Query", FieldName: "user", Alias: "u", Args: map[string]any{"id": "1"}, Path: []any{"u"}},
+		"[u name]":  {TypeName: "User", FieldName: "name", Alias: "name", Path: []any{"u", "name"}, Trivial: true},
+		"[u email]": {TypeName: "User", FieldName: "email", Alias: "email", Path: []any{"u", "email"}},
+	}
+	if !reflect.DeepEqual(infos, wantInfos) {
+		t.Errorf("wrong field info\nwant: %#v\ngot:  %#v", wantInfos, infos)
+	}
+}
@@ -0,0 +1,134 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/lru"
+)
+
+// PersistedQuery is a document registered with automatic persisted queries.
+type PersistedQuery struct {
+	// Query is the GraphQL document.
+	Query string
+
+	// The parsed document and the result of its variable-independent validation are kept so
+	// that lookups skip parsing and validation.
+	parsed validatedDocument
+}
+
+// PersistedQueryStore stores the documents of automatic persisted queries by the hex-encoded
+// SHA-256 hash of their query. Implementations must be safe for concurrent use.
+//
+// Stores which keep the *PersistedQuery values in memory (such as [NewPersistedQueryCache]) allow
+// the schema to reuse the parsed and validated document. Stores backed by external systems may
+// return a new PersistedQuery holding only the Query, which is then parsed and validated again.
+type PersistedQueryStore interface {
+	// Get returns the persisted query with the given hash.
+	Get(ctx context.Context, hash string) (*PersistedQuery, bool)
+	// Put registers a persisted query with the given hash.
+	Put(ctx context.Context, hash string, q *PersistedQuery)
+}
+
+// AutomaticPersistedQueries enables automatic persisted queries (APQ) for [Schema.ExecRequest] and
+// [Schema.SubscribeRequest] using the given store.
+//
+// A request whose extensions contain "persistedQuery" with a "sha256Hash" but no query executes the
+// stored document, or fails with the PersistedQueryNotFound error if the hash is unknown. A request
+// with both the hash and the query registers the document in the store once it passed validation.
+//
+// See https://www.apollographql.com/docs/apollo-server/performance/apq
+func AutomaticPersistedQueries(store PersistedQueryStore) SchemaOpt {
+	return func(s *Schema) {
+		s.persistedQueries = store
+	}
+}
+
+// NewPersistedQueryCache returns an in-memory [PersistedQueryStore] which holds up to size
+// documents and evicts the least recently used documents first. If size is 0 or less, the store
+// holds no documents and every lookup by hash alone fails with PersistedQueryNotFound.
+func NewPersistedQueryCache(size int) PersistedQueryStore {
+	if size <= 0 {
+		return &persistedQueryCache{}
+	}
+	return &persistedQueryCache{cache: lru.New[string, *PersistedQuery](size)}
+}
+
+type persistedQueryCache struct {
+	cache *lru.Cache[string, *PersistedQuery] // nil if the store holds no documents
+}
+
+func (c *persistedQueryCache) Get(_ context.Context, hash string) (*PersistedQuery, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	return c.cache.Get(hash)
+}
+
+func (c *persistedQueryCache) Put(_ context.Context, hash string, q *PersistedQuery) {
+	if c.cache == nil {
+		return
+	}
+	c.cache.Add(hash, q)
+}
+
+// document returns the parsed document of q validated against the schema, ignoring variables.
+func (q *PersistedQuery) document(s *Schema) (*ast.ExecutableDefinition, []*errors.QueryError) {
+	return q.parsed.document(s, q.Query)
+}
+
+// validate returns the document of q validated against the schema and the variables.
+func (q *PersistedQuery) validate(ctx context.Context, s *Schema, variables map[string]any) (*ast.ExecutableDefinition, []*errors.QueryError) {
+	return q.parsed.validate(ctx, s, q.Query, variables)
+}
+
+// persistedDocument resolves the document of a request using automatic persisted queries.
+func (s *Schema) persistedDocument(ctx context.Context, req *Request, ext any) (string, *ast.ExecutableDefinition, []*errors.QueryError) {
+	if s.persistedQueries == nil {
+		return "", nil, []*errors.QueryError{codedError("PersistedQueryNotSupported", "PERSISTED_QUERY_NOT_SUPPORTED")}
+	}
+	pq, _ := ext.(map[string]any)
+	if v, ok := pq["version"]; ok && v != 1.0 && v != 1 {
+		return "", nil, []*errors.QueryError{codedError("Unsupported persisted query version", "PERSISTED_QUERY_NOT_SUPPORTED")}
+	}
+	hash, _ := pq["sha256Hash"].(string)
+	if hash == "" {
+		return "", nil, []*errors.QueryError{errors.Errorf("persisted query hash is missing")}
+	}
+	hash = strings.ToLower(hash)
+
+	var q *PersistedQuery
+	register := req.Query != ""
+	if register {
+		sum := sha256.Sum256([]byte(req.Query))
+		if hex.EncodeToString(sum[:]) != hash {
+			return "", nil, []*errors.QueryError{codedError("provided sha does not match query", "BAD_REQUEST")}
+		}
+		q = &PersistedQuery{Query: req.Query}
+	} else {
+		var ok bool
+		if q, ok = s.persistedQueries.Get(ctx, hash); !ok {
+			return "", nil, []*errors.QueryError{codedError("PersistedQueryNotFound", "PERSISTED_QUERY_NOT_FOUND")}
+		}
+	}
+
+	doc, errs := q.validate(ctx, s, req.Variables)
+	if register {
+		if _, docErrs := q.document(s); len(docErrs) == 0 {
+			s.persistedQueries.Put(ctx, hash, q)
+		}
+	}
+	return q.Query, doc, errs
+}
+
+// codedError returns an error with the given code in its extensions.
+func codedError(message, code string) *errors.QueryError {
+	return &errors.QueryError{
+		Message:    message,
+		Extensions: map[string]any{"code": code},
+	}
+}
@@ -0,0 +1,176 @@
+package graphql_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func persistedQueryExt(hash string) map[string]any {
+	return map[string]any{"persistedQuery": map[string]any{"version": 1.0, "sha256Hash": hash}}
+}
+
+func responseJSON(t *testing.T, resp *graphql.Response) string {
+	t.Helper()
+	b, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestAutomaticPersistedQueries(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.AutomaticPersistedQueries(graphql.NewPersistedQueryCache(10)))
+	ctx := context.Background()
+
+	const q = `query($id: ID!) { character(id: $id) { name } }`
+	hash := sha256Hex(q)
+
+	for _, step := range []struct {
+		name string
+		req  *graphql.Request
+		want string
+	}{
+		{
+			name: "unknown hash",
+			req:  &graphql.Request{Variables: map[string]any{"id": "1000"}, Extensions: persistedQueryExt(hash)},
+			want: `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`,
+		},
+		{
+			name: "hash mismatch",
+			req:  &graphql.Request{Query: "{ hero { name } }", Extensions: persistedQueryExt(hash)},
+			want: `{"errors":[{"message":"provided sha does not match query","extensions":{"code":"BAD_REQUEST"}}]}`,
+		},
+		{
+			name: "register",
+			req:  &graphql.Request{Query: q, Variables: map[string]any{"id": "1000"}, Extensions: persistedQueryExt(hash)},
+			want: `{"data":{"character":{"name":"Luke Skywalker"}}}`,
+		},
+		{
+			name: "lookup",
+			req:  &graphql.Request{Variables: map[string]any{"id": "1001"}, Extensions: persistedQueryExt(hash)},
+			want: `{"data":{"character":{"name":"Darth Vader"}}}`,
+		},
+		{
+			name: "lookup validates variables",
+			req:  &graphql.Request{Extensions: persistedQueryExt(hash)},
+			want: `{"errors":[{"message":"Variable \"id\" has invalid value null.\nExpected type \"ID!\", found null.","locations":[{"line":1,"column":7}]}]}`,
+		},
+		{
+			name: "unsupported version",
+			req:  &graphql.Request{Extensions: map[string]any{"persistedQuery": map[string]any{"version": 2.0, "sha256Hash": hash}}},
+			want: `{"errors":[{"message":"Unsupported persisted query version","extensions":{"code":"PERSISTED_QUERY_NOT_SUPPORTED"}}]}`,
+		},
+	} {
+		t.Run(step.name, func(t *testing.T) {
+			if got := responseJSON(t, schema.ExecRequest(ctx, step.req)); got != step.want {
+				t.Errorf("unexpected response:\n got: %s\nwant: %s", got, step.want)
+			}
+		})
+	}
+
+	t.Run("invalid documents are not registered", func(t *testing.T) {
+		const invalid = `{ unknown }`
+		resp := schema.ExecRequest(ctx, &graphql.Request{Query: invalid, Extensions: persistedQueryExt(sha256Hex(invalid))})
+		if len(resp.Errors) != 1 || resp.Errors[0].Rule != "FieldsOnCorrectTypeRule" {
+			t.Fatalf("expected a validation error, got %s", responseJSON(t, resp))
+		}
+		resp = schema.ExecRequest(ctx, &graphql.Request{Extensions: persistedQueryExt(sha256Hex(invalid))})
+		if len(resp.Errors) != 1 || resp.Errors[0].Message != "PersistedQueryNotFound" {
+			t.Fatalf("expected PersistedQueryNotFound, got %s", responseJSON(t, resp))
+		}
+	})
+
+	t.Run("concurrent lookups", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp := schema.ExecRequest(ctx, &graphql.Request{Variables: map[string]any{"id": "1000"}, Extensions: persistedQueryExt(hash)})
+				if len(resp.Errors) != 0 {
+					t.Errorf("unexpected errors: %v", resp.Errors)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestAutomaticPersistedQueriesNotSupported(t *testing.T) {
+	const q = `{ hero { name } }`
+	resp := starwarsSchema.ExecRequest(context.Background(), &graphql.Request{Query: q, Extensions: persistedQueryExt(sha256Hex(q))})
+	want := `{"errors":[{"message":"PersistedQueryNotSupported","extensions":{"code":"PERSISTED_QUERY_NOT_SUPPORTED"}}]}`
+	if got := responseJSON(t, resp); got != want {
+		t.Errorf("unexpected response:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestPersistedQueryCacheZeroSize(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.AutomaticPersistedQueries(graphql.NewPersistedQueryCache(0)))
+	ctx := context.Background()
+
+	const q = `{ hero { name } }`
+	resp := schema.ExecRequest(ctx, &graphql.Request{Query: q, Extensions: persistedQueryExt(sha256Hex(q))})
+	if got, want := responseJSON(t, resp), `{"data":{"hero":{"name":"R2-D2"}}}`; got != want {
+		t.Errorf("register:\n got: %s\nwant: %s", got, want)
+	}
+	resp = schema.ExecRequest(ctx, &graphql.Request{Extensions: persistedQueryExt(sha256Hex(q))})
+	want := `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`
+	if got := responseJSON(t, resp); got != want {
+		t.Errorf("lookup:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+// externalStore keeps only the query strings, like a store backed by an external cache.
+type externalStore struct {
+	mu      sync.Mutex
+	queries map[string]string
+}
+
+func (s *externalStore) Get(_ context.Context, hash string) (*graphql.PersistedQuery, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.queries[hash]
+	if !ok {
+		return nil, false
+	}
+	return &graphql.PersistedQuery{Query: q}, true
+}
+
+func (s *externalStore) Put(_ context.Context, hash string, q *graphql.PersistedQuery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries[hash] = q.Query
+}
+
+func TestAutomaticPersistedQueriesExternalStore(t *testing.T) {
+	store := &externalStore{queries: map[string]string{}}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.AutomaticPersistedQueries(store))
+	ctx := context.Background()
+
+	const q = `{ hero { name } }`
+	for _, req := range []*graphql.Request{
+		{Query: q, Extensions: persistedQueryExt(sha256Hex(q))},
+		{Extensions: persistedQueryExt(sha256Hex(q))},
+	} {
+		want := `{"data":{"hero":{"name":"R2-D2"}}}`
+		if got := responseJSON(t, schema.ExecRequest(ctx, req)); got != want {
+			t.Errorf("unexpected response:\n got: %s\nwant: %s", got, want)
+		}
+	}
+	if len(store.queries) != 1 {
+		t.Errorf("expected 1 stored query, got %d", len(store.queries))
+	}
+}
@@ -0,0 +1,119 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// Plugin runs logic at the phases of a request, e.g. for request logging, enforcing policies on
+// documents, injecting response extensions or rewriting errors. Unlike tracers, the hooks of a
+// plugin may abort the request or modify it. Embed [BasePlugin] to implement only some hooks.
+//
+// The hooks run for every request, including requests whose document is served from the
+// [DocumentCache], persisted queries, trusted documents and prepared operations. Since documents
+// may be shared by requests, hooks must not modify them.
+type Plugin interface {
+	// AfterParse is called with the parsed document. Returning errors aborts the request with the
+	// errors.
+	AfterParse(ctx context.Context, doc *ast.ExecutableDefinition) []*errors.QueryError
+
+	// AfterValidation is called with the validated document and the validation errors, and
+	// returns the errors of the request, which may be modified. Returning errors aborts the
+	// request with the errors. Validation errors can be rewritten or added to, but not cleared:
+	// if a plugin returns no errors for an invalid document, the errors passed to it are kept.
+	AfterValidation(ctx context.Context, doc *ast.ExecutableDefinition, errs []*errors.QueryError) []*errors.QueryError
+
+	// BeforeExecution is called with the selected operation and its variables before the
+	// operation is executed. The variables may be modified. The returned context is used for the
+	// execution. Returning errors aborts the request with the errors.
+	BeforeExecution(ctx context.Context, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]any) (context.Context, []*errors.QueryError)
+
+	// AfterExecution is called with the response of an executed query or mutation, which may be
+	// modified. For incremental executions, it is called with the initial response.
+	AfterExecution(ctx context.Context, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]any, resp *Response)
+
+	// OnSubscriptionEvent is called with the response of each event of a subscription, which may
+	// be modified.
+	OnSubscriptionEvent(ctx context.Context, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]any, resp *Response)
+}
+
+// BasePlugin implements the hooks of [Plugin] without doing anything.
+type BasePlugin struct{}
+
+// AfterParse implements [Plugin].
+func (BasePlugin) AfterParse(context.Context, *ast.ExecutableDefinition) []*errors.QueryError {
+	return nil
+}
+
+// AfterValidation implements [Plugin].
+func (BasePlugin) AfterValidation(_ context.Context, _ *ast.ExecutableDefinition, errs []*errors.QueryError) []*errors.QueryError {
+	return errs
+}
+
+// BeforeExecution implements [Plugin].
+func (BasePlugin) BeforeExecution(ctx context.Context, _ *ast.ExecutableDefinition, _ *ast.OperationDefinition, _ map[string]any) (context.Context, []*errors.QueryError) {
+	return ctx, nil
+}
+
+// AfterExecution implements [Plugin].
+func (BasePlugin) AfterExecution(context.Context, *ast.ExecutableDefinition, *ast.OperationDefinition, map[string]any, *Response) {
+}
+
+// OnSubscriptionEvent implements [Plugin].
+func (BasePlugin) OnSubscriptionEvent(context.Context, *ast.ExecutableDefinition, *ast.OperationDefinition, map[string]any, *Response) {
+}
+
+// UsePlugins registers plugins. The hooks of the plugins are called in the order in which the
+// plugins were registered. The first plugin aborting a request in AfterParse or BeforeExecution
+// stops the remaining hooks, while AfterValidation passes the errors from one plugin to the next.
+// Multiple calls append plugins. The hooks do not run for the introspection query of
+// [Schema.ToJSON].
+func UsePlugins(plugins ...Plugin) SchemaOpt {
+	return func(s *Schema) {
+		s.plugins = append(s.plugins[:len(s.plugins):len(s.plugins)], plugins...)
+	}
+}
+
+func (s *Schema) afterParse(ctx context.Context, doc *ast.ExecutableDefinition) []*errors.QueryError {
+	for _, p := range s.plugins {
+		if errs := p.AfterParse(ctx, doc); len(errs) != 0 {
+			return errs
+		}
+	}
+	return nil
+}
+
+func (s *Schema) afterValidation(ctx context.Context, doc *ast.ExecutableDefinition, errs []*errors.QueryError) []*errors.QueryError {
+	for _, p := range s.plugins {
+		// An invalid document must never be executed, so plugins cannot clear the errors.
+		if pluginErrs := p.AfterValidation(ctx, doc, errs); len(pluginErrs) != 0 || len(errs) == 0 {
+			errs = pluginErrs
+		}
+	}
+	return errs
+}
+
+func (s *Schema) beforeExecution(ctx context.Context, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]any) (context.Context, []*errors.QueryError) {
+	for _, p := range s.plugins {
+		var errs []*errors.QueryError
+		ctx, errs = p.BeforeExecution(ctx, doc, op, variables)
+		if len(errs) != 0 {
+			return ctx, errs
+		}
+	}
+	return ctx, nil
+}
+
+func (s *Schema) afterExecution(ctx context.Context, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]any, resp *Response) {
+	for _, p := range s.plugins {
+		p.AfterExecution(ctx, doc, op, variables, resp)
+	}
+}
+
+func (s *Schema) onSubscriptionEvent(ctx context.Context, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]any, resp *Response) {
+	for _, p := range s.plugins {
+		p.OnSubscriptionEvent(ctx, doc, op, variables, resp)
+	}
+}
@@ -0,0 +1,327 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/ast"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/trace/noop"
+)
+
+const pluginSchema = `
+	type Query {
+		greet(name: String! = "world"): String!
+		user: String!
+	}
+	type Subscription {
+		counter: Int!
+	}
+`
+
+type pluginUserKey struct{}
+
+type pluginResolver struct{}
+
+func (pluginResolver) Greet(args struct{ Name string }) string {
+	return "hello " + args.Name
+}
+
+func (pluginResolver) User(ctx context.Context) string {
+	user, _ := ctx.Value(pluginUserKey{}).(string)
+	return user
+}
+
+func (pluginResolver) Counter(ctx context.Context) <-chan int32 {
+	c := make(chan int32, 2)
+	c <- 1
+	c <- 2
+	close(c)
+	return c
+}
+
+// recordingPlugin records the hooks it was called with.
+type recordingPlugin struct {
+	graphql.BasePlugin
+	name  string
+	calls *[]string
+}
+
+func (p *recordingPlugin) AfterParse(ctx context.Context, doc *ast.ExecutableDefinition) []*qerrors.QueryError {
+	*p.calls = append(*p.calls, fmt.Sprintf("%s AfterParse %d", p.name, len(doc.Operations)))
+	return nil
+}
+
+func (p *recordingPlugin) AfterValidation(ctx context.Context, doc *ast.ExecutableDefinition, errs []*qerrors.QueryError) []*qerrors.QueryError {
+	*p.calls = append(*p.calls, fmt.Sprintf("%s AfterValidation %d", p.name, len(errs)))
+	return errs
+}
+
+func (p *recordingPlugin) BeforeExecution(ctx context.Context, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]any) (context.Context, []*qerrors.QueryError) {
+	*p.calls = append(*p.calls, fmt.Sprintf("%s BeforeExecution %s %v", p.name, op.Type, variables))
+	return ctx, nil
+}
+
+func (p *recordingPlugin) AfterExecution(ctx context.Context, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]any, resp *graphql.Response) {
+	*p.calls = append(*p.calls, fmt.Sprintf("%s AfterExecution %s", p.name, resp.Data))
+}
+
+func (p *recordingPlugin) OnSubscriptionEvent(ctx context.Context, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]any, resp *graphql.Response) {
+	*p.calls = append(*p.calls, fmt.Sprintf("%s OnSubscriptionEvent %s", p.name, resp.Data))
+}
+
+func TestPluginHooks(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []graphql.SchemaOpt
+	}{
+		{name: "without cache"},
+		{name: "with cache", opts: []graphql.SchemaOpt{graphql.DocumentCache(10)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			opts := append(tc.opts, graphql.UsePlugins(&recordingPlugin{name: "a", calls: &calls}), graphql.UsePlugins(&recordingPlugin{name: "b", calls: &calls}))
+			s := graphql.MustParseSchema(pluginSchema, &pluginResolver{}, opts...)
+			for range 2 {
+				calls = nil
+				res := s.Exec(context.Background(), `query Q($name: String = "you") { greet(name: $name) }`, "", nil)
+				if len(res.Errors) != 0 {
+					t.Fatal(res.Errors)
+				}
+				want := []string{
+					"a AfterParse 1",
+					"b AfterParse 1",
+					"a AfterValidation 0",
+					"b AfterValidation 0",
+					"a BeforeExecution QUERY map[name:you]",
+					"b BeforeExecution QUERY map[name:you]",
+					`a AfterExecution {"greet":"hello you"}`,
+					`b AfterExecution {"greet":"hello you"}`,
+				}
+				if !slices.Equal(calls, want) {
+					t.Errorf("got calls\n%s\nwant\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+				}
+			}
+		})
+	}
+}
+
+// validationRecorder records the validation spans in the calls of a recordingPlugin.
+type validationRecorder struct {
+	noop.Tracer
+	calls *[]string
+}
+
+func (t *validationRecorder) TraceValidation(ctx context.Context) func([]*qerrors.QueryError) {
+	*t.calls = append(*t.calls, "TraceValidation")
+	return func(errs []*qerrors.QueryError) {
+		*t.calls = append(*t.calls, fmt.Sprintf("TraceValidation finished %d", len(errs)))
+	}
+}
+
+func TestPluginHooksValidationOrder(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []graphql.SchemaOpt
+	}{
+		{name: "without cache"},
+		{name: "with cache", opts: []graphql.SchemaOpt{graphql.DocumentCache(10)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			opts := append(tc.opts, graphql.UsePlugins(&recordingPlugin{name: "a", calls: &calls}), graphql.Tracer(&validationRecorder{calls: &calls}))
+			s := graphql.MustParseSchema(pluginSchema, &pluginResolver{}, opts...)
+			for range 2 {
+				calls = nil
+				s.Exec(context.Background(), `{ unknown }`, "", nil)
+				want := []string{
+					"a AfterParse 1",
+					"TraceValidation",
+					"TraceValidation finished 1",
+					"a AfterValidation 1",
+				}
+				if !slices.Equal(calls, want) {
+					t.Errorf("got calls\n%s\nwant\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+				}
+
+				calls = nil
+				s.Exec(context.Background(), `{`, "", nil)
+				if len(calls) != 0 {
+					t.Errorf("unexpected calls for a syntax error\n%s", strings.Join(calls, "\n"))
+				}
+			}
+		})
+	}
+}
+
+func TestPluginSubscriptionEvents(t *testing.T) {
+	var calls []string
+	s := graphql.MustParseSchema(pluginSchema, &pluginResolver{}, graphql.UsePlugins(&recordingPlugin{name: "a", calls: &calls}))
+	c, err := s.Subscribe(context.Background(), `subscription { counter }`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range c {
+	}
+	want := []string{
+		"a AfterParse 1",
+		"a AfterValidation 0",
+		"a BeforeExecution SUBSCRIPTION map[]",
+		`a OnSubscriptionEvent {"counter":1}`,
+		`a OnSubscriptionEvent {"counter":2}`,
+	}
+	if !slices.Equal(calls, want) {
+		t.Errorf("got calls\n%s\nwant\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// policyPlugin rejects documents with more than one operation, rewrites validation errors,
+// rejects anonymous operations, sets the user and adds the number of errors to the response
+// extensions.
+type policyPlugin struct {
+	graphql.BasePlugin
+}
+
+func (policyPlugin) AfterParse(ctx context.Context, doc *ast.ExecutableDefinition) []*qerrors.QueryError {
+	if len(doc.Operations) > 1 {
+		return []*qerrors.QueryError{qerrors.Errorf("only one operation is allowed")}
+	}
+	return nil
+}
+
+func (policyPlugin) AfterValidation(ctx context.Context, doc *ast.ExecutableDefinition, errs []*qerrors.QueryError) []*qerrors.QueryError {
+	if len(errs) == 0 {
+		return nil
+	}
+	return []*qerrors.QueryError{qerrors.Errorf("invalid query")}
+}
+
+func (policyPlugin) BeforeExecution(ctx context.Context, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]any) (context.Context, []*qerrors.QueryError) {
+	if op.Name.Name == "" {
+		return ctx, []*qerrors.QueryError{qerrors.Errorf("anonymous operations are not allowed")}
+	}
+	variables["name"] = "admin"
+	return context.WithValue(ctx, pluginUserKey{}, "admin"), nil
+}
+
+func (policyPlugin) AfterExecution(ctx context.Context, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]any, resp *graphql.Response) {
+	if resp.Extensions == nil {
+		resp.Extensions = make(map[string]any)
+	}
+	resp.Extensions["errors"] = len(resp.Errors)
+}
+
+func TestPluginModifyAndAbort(t *testing.T) {
+	s := graphql.MustParseSchema(pluginSchema, &pluginResolver{}, graphql.UsePlugins(policyPlugin{}))
+	for _, tc := range []struct {
+		name      string
+		query     string
+		variables map[string]any
+		want      string
+	}{
+		{
+			name:  "after parse",
+			query: `query A { user } query B { user }`,
+			want:  `{"errors":[{"message":"only one operation is allowed"}]}`,
+		},
+		{
+			name:  "after validation",
+			query: `{ unknown }`,
+			want:  `{"errors":[{"message":"invalid query"}]}`,
+		},
+		{
+			name:  "before execution",
+			query: `{ user }`,
+			want:  `{"errors":[{"message":"anonymous operations are not allowed"}]}`,
+		},
+		{
+			name:      "modified request",
+			query:     `query Q($name: String!) { user greet(name: $name) }`,
+			variables: map[string]any{"name": "guest"},
+			want:      `{"data":{"user":"admin","greet":"hello admin"},"extensions":{"errors":0}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := s.Exec(context.Background(), tc.query, "", tc.variables)
+			got, err := json.Marshal(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+// clearingPlugin tries to clear the validation errors.
+type clearingPlugin struct {
+	graphql.BasePlugin
+}
+
+func (clearingPlugin) AfterValidation(ctx context.Context, doc *ast.ExecutableDefinition, errs []*qerrors.QueryError) []*qerrors.QueryError {
+	return nil
+}
+
+func TestPluginCannotClearValidationErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []graphql.SchemaOpt
+	}{
+		{name: "without cache"},
+		{name: "with cache", opts: []graphql.SchemaOpt{graphql.DocumentCache(10)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			opts := append(tc.opts, graphql.UsePlugins(clearingPlugin{}, &recordingPlugin{name: "a", calls: &calls}))
+			s := graphql.MustParseSchema(pluginSchema, &pluginResolver{}, opts...)
+			res := s.Exec(context.Background(), `{ unknown }`, "", nil)
+			want := `{"errors":[{"message":"Cannot query field \"unknown\" on type \"Query\".","locations":[{"line":1,"column":3}]}]}`
+			if got := responseJSON(t, res); got != want {
+				t.Errorf("got %s, want %s", got, want)
+			}
+			// The following plugins see the validation errors, and the document is not executed.
+			if want := []string{"a AfterParse 1", "a AfterValidation 1"}; !slices.Equal(calls, want) {
+				t.Errorf("got calls %q, want %q", calls, want)
+			}
+		})
+	}
+}
+
+func TestPluginsSkipToJSON(t *testing.T) {
+	var calls []string
+	s := graphql.MustParseSchema(pluginSchema, &pluginResolver{}, graphql.UsePlugins(&recordingPlugin{name: "a", calls: &calls}))
+	if _, err := s.ToJSON(); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("unexpected calls %q", calls)
+	}
+}
+
+func TestPluginPreparedOperation(t *testing.T) {
+	var calls []string
+	s := graphql.MustParseSchema(pluginSchema, &pluginResolver{}, graphql.UsePlugins(&recordingPlugin{name: "a", calls: &calls}))
+	p, errs := s.Prepare(`{ greet }`, "")
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	if len(calls) != 0 {
+		t.Errorf("hooks called by Prepare: %v", calls)
+	}
+	p.Exec(context.Background(), nil)
+	want := []string{
+		"a AfterParse 1",
+		"a AfterValidation 0",
+		"a BeforeExecution QUERY map[]",
+		`a AfterExecution {"greet":"hello world"}`,
+	}
+	if !slices.Equal(calls, want) {
+		t.Errorf("got calls\n%s\nwant\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+	}
+}
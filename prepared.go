@@ -0,0 +1,143 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/common"
+	"github.com/graph-gophers/graphql-go/internal/validation"
+)
+
+// PreparedOperation is an operation which has been parsed, validated and selected once, so that
+// it can be executed many times with different variables.
+type PreparedOperation struct {
+	schema     *Schema
+	query      string
+	doc        *ast.ExecutableDefinition
+	op         *ast.OperationDefinition
+	vars       []VariableDefinition
+	rootFields []string
+}
+
+// VariableDefinition is a variable declared by a [PreparedOperation].
+type VariableDefinition struct {
+	Name string
+	// Type is the type of the variable, resolved against the schema.
+	Type ast.Type
+	// Default is the default value of the variable or nil if it has none.
+	Default ast.Value
+}
+
+// Prepare parses and validates the query and selects the operation with the given name, which may
+// be empty if the query contains a single operation. The validation rules that depend on variables
+// run whenever the operation is executed.
+//
+// Prepare is intended for operations issued by the server itself, such as background jobs, and
+// does not enforce [TrustedDocuments].
+func (s *Schema) Prepare(queryString string, operationName string) (*PreparedOperation, []*qerrors.QueryError) {
+	var d validatedDocument
+	doc, errs := d.document(s, queryString)
+	if len(errs) != 0 {
+		return nil, errs
+	}
+	op, err := getOperation(doc, operationName)
+	if err != nil {
+		return nil, []*qerrors.QueryError{qerrors.Errorf("%s", err)}
+	}
+	vars := make([]VariableDefinition, len(op.Vars))
+	for i, v := range op.Vars {
+		t, err := common.ResolveType(v.Type, s.schema.Resolve)
+		if err != nil {
+			return nil, []*qerrors.QueryError{err}
+		}
+		vars[i] = VariableDefinition{Name: v.Name.Name, Type: t, Default: v.Default}
+	}
+	return &PreparedOperation{
+		schema:     s,
+		query:      queryString,
+		doc:        doc,
+		op:         op,
+		vars:       vars,
+		rootFields: rootFields(doc, op.Selections, nil, make(map[string]bool)),
+	}, nil
+}
+
+// Type returns the type of the operation, i.e. "QUERY", "MUTATION" or "SUBSCRIPTION".
+func (p *PreparedOperation) Type() ast.OperationType {
+	return p.op.Type
+}
+
+// Name returns the name of the operation, which is empty for anonymous operations.
+func (p *PreparedOperation) Name() string {
+	return p.op.Name.Name
+}
+
+// VariableDefinitions returns the variables declared by the operation.
+func (p *PreparedOperation) VariableDefinitions() []VariableDefinition {
+	return p.vars
+}
+
+// RootFields returns the names of the fields selected on the root type of the operation,
+// including the fields of fragments, in the order of their first selection.
+func (p *PreparedOperation) RootFields() []string {
+	return p.rootFields
+}
+
+// Exec executes the operation with the given variables like [Schema.Exec].
+func (p *PreparedOperation) Exec(ctx context.Context, variables map[string]any) *Response {
+	s := p.schema
+	if !s.res.QueryResolver.IsValid() {
+		panic("schema created without resolver, can not exec")
+	}
+	if errs := p.validateVariables(ctx, variables); len(errs) != 0 {
+		return &Response{Errors: errs}
+	}
+	resp, _ := s.execOperation(ctx, p.query, p.doc, p.op, variables, s.res, false)
+	return resp
+}
+
+// Subscribe subscribes to the operation with the given variables like [Schema.Subscribe].
+func (p *PreparedOperation) Subscribe(ctx context.Context, variables map[string]any) (<-chan any, error) {
+	s := p.schema
+	if !s.res.SubscriptionResolver.IsValid() {
+		return nil, errors.New("schema created without resolver, can not subscribe")
+	}
+	if _, ok := s.schema.RootOperationTypes["subscription"]; !ok {
+		return nil, errors.New("no subscriptions are offered by the schema")
+	}
+	if errs := p.validateVariables(ctx, variables); len(errs) != 0 {
+		return sendAndReturnClosed(&Response{Errors: errs}), nil
+	}
+	return s.subscribeOperation(ctx, p.doc, p.op, variables, s.res), nil
+}
+
+func (p *PreparedOperation) validateVariables(ctx context.Context, variables map[string]any) []*qerrors.QueryError {
+	if errs := p.schema.afterParse(ctx, p.doc); errs != nil {
+		return errs
+	}
+	validationFinish := p.schema.validationTracer.TraceValidation(ctx)
+	errs := validation.ValidateVariables(p.schema.schema, p.doc, variables)
+	validationFinish(errs)
+	return p.schema.afterValidation(ctx, p.doc, errs)
+}
+
+func rootFields(doc *ast.ExecutableDefinition, sels ast.SelectionSet, names []string, seen map[string]bool) []string {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if !seen[sel.Name.Name] {
+				seen[sel.Name.Name] = true
+				names = append(names, sel.Name.Name)
+			}
+		case *ast.InlineFragment:
+			names = rootFields(doc, sel.Selections, names, seen)
+		case *ast.FragmentSpread:
+			if frag := doc.Fragments.Get(sel.Name.Name); frag != nil {
+				names = rootFields(doc, frag.Selections, names, seen)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,157 @@
+package graphql_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/ast"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+)
+
+func TestPrepare(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})
+	ctx := context.Background()
+
+	op, errs := schema.Prepare(`
+		query First { hero { name } }
+		query Character($id: ID!) {
+			character(id: $id) { name }
+			... on Query { hero { id } }
+			...Root
+		}
+		fragment Root on Query { character(id: $id) { id } droid(id: "2000") { name } }
+	`, "Character")
+	if errs != nil {
+		t.Fatal(errs)
+	}
+
+	if op.Type() != "QUERY" || op.Name() != "Character" {
+		t.Errorf("wrong operation %s %s", op.Type(), op.Name())
+	}
+	if vars := op.VariableDefinitions(); len(vars) != 1 || vars[0].Name != "id" || vars[0].Type.String() != "ID!" {
+		t.Errorf("wrong variable definitions %v", vars)
+	}
+	if got, want := op.RootFields(), []string{"character", "hero", "droid"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong root fields\nwant: %v\ngot:  %v", want, got)
+	}
+
+	for id, want := range map[string]string{
+		"1000": `{"data":{"character":{"name":"Luke Skywalker","id":"1000"},"hero":{"id":"2001"},"droid":{"name":"C-3PO"}}}`,
+		"1001": `{"data":{"character":{"name":"Darth Vader","id":"1001"},"hero":{"id":"2001"},"droid":{"name":"C-3PO"}}}`,
+	} {
+		if got := responseJSON(t, op.Exec(ctx, map[string]any{"id": id})); got != want {
+			t.Errorf("wrong response for %s\nwant: %s\ngot:  %s", id, want, got)
+		}
+	}
+
+	want := `{"errors":[{"message":"Variable \"id\" has invalid value null.\nExpected type \"ID!\", found null.","locations":[{"line":3,"column":19}]}]}`
+	if got := responseJSON(t, op.Exec(ctx, nil)); got != want {
+		t.Errorf("wrong response without variables\nwant: %s\ngot:  %s", want, got)
+	}
+}
+
+func TestPrepareErrors(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})
+
+	for _, tc := range []struct {
+		query         string
+		operationName string
+		want          string
+	}{
+		{query: `{ hero { unknown } }`, want: `Cannot query field "unknown" on type "Character".`},
+		{query: `{ hero { name }`, want: `syntax error: unexpected "", expecting Ident`},
+		{query: `query A { hero { name } } query B { hero { id } }`, want: `more than one operation in query document and no operation name given`},
+		{query: `{ hero { name } }`, operationName: "Unknown", want: `no operation with name "Unknown"`},
+	} {
+		op, errs := schema.Prepare(tc.query, tc.operationName)
+		if op != nil || len(errs) != 1 || errs[0].Message != tc.want {
+			t.Errorf("%s: want error %q, got %v", tc.query, tc.want, errs)
+		}
+	}
+}
+
+func TestPreparedSubscribe(t *testing.T) {
+	schema := graphql.MustParseSchema(schema, &rootResolver{
+		helloSaidResolver: &helloSaidResolver{upstream: closedUpstream(&helloSaidEventResolver{msg: "Hello world!"})},
+	})
+
+	op, errs := schema.Prepare(`subscription { helloSaid { msg } }`, "")
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	if op.Type() != "SUBSCRIPTION" {
+		t.Errorf("wrong operation type %s", op.Type())
+	}
+	c, err := op.Subscribe(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := responseJSON(t, (<-c).(*graphql.Response)), `{"data":{"helloSaid":{"msg":"Hello world!"}}}`; got != want {
+		t.Errorf("unexpected response:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+type operationTypeResolver struct{}
+
+func (operationTypeResolver) Hello() string                      { return "hello" }
+func (operationTypeResolver) SetHello(struct{ S string }) string { return "hello" }
+func (operationTypeResolver) Hellos() <-chan string              { return nil }
+
+func TestOperationType(t *testing.T) {
+	const sub = `subscription OnHello { hellos }`
+	var reports int
+	hash := sha256Hex(sub)
+	s := graphql.MustParseSchema(`
+		type Query { hello: String! }
+		type Mutation { setHello(s: String!): String! }
+		type Subscription { hellos: String! }
+	`, &operationTypeResolver{},
+		graphql.MaxTokens(16),
+		graphql.DocumentCache(10),
+		graphql.AutomaticPersistedQueries(graphql.NewPersistedQueryCache(10)),
+		graphql.TrustedDocumentsLogOnly(graphql.TrustedDocumentManifest{"doc-1": `mutation { setHello(s: "hi") }`}, func(context.Context, string, *qerrors.QueryError) {
+			reports++
+		}),
+	)
+	ctx := context.Background()
+
+	// Register the persisted query.
+	if _, err := s.SubscribeRequest(ctx, &graphql.Request{Query: sub, Extensions: persistedQueryExt(hash)}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		req  *graphql.Request
+		want ast.OperationType
+		ok   bool
+	}{
+		{name: "query", req: &graphql.Request{Query: `query A { hello } mutation B { setHello(s: "") }`, OperationName: "B"}, want: "MUTATION", ok: true},
+		{name: "persisted query", req: &graphql.Request{Extensions: persistedQueryExt(hash)}, want: "SUBSCRIPTION", ok: true},
+		{name: "document id", req: &graphql.Request{DocumentID: "doc-1"}, want: "MUTATION", ok: true},
+		{name: "unknown hash", req: &graphql.Request{Extensions: persistedQueryExt(sha256Hex("{ hello }"))}},
+		{name: "unknown operation", req: &graphql.Request{Query: sub, OperationName: "Other"}},
+		{name: "too many tokens", req: &graphql.Request{Query: `{ a: hello b: hello c: hello d: hello e: hello f: hello }`}},
+		{name: "syntax error", req: &graphql.Request{Query: `{`}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := s.OperationType(ctx, tc.req)
+			if got != tc.want || ok != tc.ok {
+				t.Errorf("got %q, %v, want %q, %v", got, ok, tc.want, tc.ok)
+			}
+
+			// Executing the request reuses the document resolved by OperationType.
+			stats, reported := s.DocumentCacheStats(), reports
+			s.ExecRequest(ctx, tc.req)
+			if got := s.DocumentCacheStats(); got.Hits != stats.Hits || got.Misses != stats.Misses {
+				t.Errorf("document cache counters changed from %+v to %+v", stats, got)
+			}
+			if reports != reported {
+				t.Errorf("untrusted document was reported again")
+			}
+		})
+	}
+}
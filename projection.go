@@ -0,0 +1,225 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go/ast"
+)
+
+// Projection holds the columns of a model which are needed for the fields selected in a query,
+// see [SelectedProjection].
+type Projection struct {
+	// Columns are the columns of the selected fields in the order of the struct fields.
+	Columns []string
+	// Relations holds the projections of the selected relations, keyed by their column tag.
+	Relations map[string]*Projection
+}
+
+// Qualified returns the columns of the projection followed by the columns of its relations, which
+// are qualified with the keys of the relations, e.g. "author.name". Relations are ordered by key.
+// The qualified names match the column names of nested structs used by libraries such as sqlx.
+func (p *Projection) Qualified() []string {
+	if p == nil {
+		return nil
+	}
+	cols := slices.Clone(p.Columns)
+	keys := make([]string, 0, len(p.Relations))
+	for key := range p.Relations {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		for _, col := range p.Relations[key].Qualified() {
+			cols = append(cols, key+"."+col)
+		}
+	}
+	return cols
+}
+
+// SelectedProjection maps the fields selected underneath the current resolver (see
+// [SelectionSet]) to the fields of a model struct and returns the columns they need. The model is
+// a struct or a pointer to a struct, whose fields are mapped to GraphQL fields by the graphql tag
+// and to columns by the tag named by tag, e.g. "db":
+//
+//	type Book struct {
+//		ID     string  `graphql:"id" db:"id"`
+//		Title  string  `graphql:"title" db:"book_title"`
+//		Author *Author `graphql:"author" db:"author"`
+//	}
+//
+// Struct fields without a graphql tag match the GraphQL field of the same name, ignoring case
+// and underscores, like [UseFieldResolvers]. Struct fields without a column tag or tagged with
+// "-" yield no column, and options following a comma in the tags are ignored. Embedded structs
+// are flattened.
+//
+// A struct field whose type is a struct, a pointer to a struct or a slice of those is a relation
+// if the type of the selected GraphQL field is an object, interface or union type. Relations are
+// projected recursively into [Projection.Relations], keyed by their column tag or, without one, by
+// their GraphQL name. Other struct fields mapped to such GraphQL fields yield their column, e.g.
+// a foreign key.
+//
+// Fields selected multiple times, e.g. with aliases or in fragments, yield a single column. The
+// projection is empty when the current field's return type is a leaf or when
+// DisableFieldSelections was used at schema creation. An error is returned if the model is not a
+// struct.
+func SelectedProjection(ctx context.Context, model any, tag string) (*Projection, error) {
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("graphql: projection model must be a struct, got %T", model)
+	}
+	return project(modelFieldsOf(t, tag), SelectionSet(ctx), tag), nil
+}
+
+func project(fields []modelField, sels []SelectedField, tag string) *Projection {
+	p := &Projection{}
+	for _, f := range fields {
+		var match *SelectedField
+		var children []SelectedField
+		for i := range sels {
+			if f.matches(sels[i].Name) {
+				match = &sels[i]
+				children = append(children, sels[i].Selections...)
+			}
+		}
+		if match == nil {
+			continue
+		}
+		if f.relation != nil && isCompositeType(match.Definition.Type) {
+			key := f.column
+			if key == "" {
+				key = match.Name
+			}
+			if p.Relations == nil {
+				p.Relations = make(map[string]*Projection)
+			}
+			rel := project(modelFieldsOf(f.relation, tag), children, tag)
+			if prev, ok := p.Relations[key]; ok {
+				rel = mergeProjections(prev, rel)
+			}
+			p.Relations[key] = rel
+			continue
+		}
+		if f.column != "" && !slices.Contains(p.Columns, f.column) {
+			p.Columns = append(p.Columns, f.column)
+		}
+	}
+	return p
+}
+
+func mergeProjections(a, b *Projection) *Projection {
+	for _, col := range b.Columns {
+		if !slices.Contains(a.Columns, col) {
+			a.Columns = append(a.Columns, col)
+		}
+	}
+	for key, rel := range b.Relations {
+		if prev, ok := a.Relations[key]; ok {
+			rel = mergeProjections(prev, rel)
+		}
+		if a.Relations == nil {
+			a.Relations = make(map[string]*Projection)
+		}
+		a.Relations[key] = rel
+	}
+	return a
+}
+
+// modelField is a field of a model struct which is mapped to a GraphQL field.
+type modelField struct {
+	name     string       // name of the GraphQL field
+	tagged   bool         // whether name was set by the graphql tag
+	column   string       // value of the column tag
+	relation reflect.Type // struct type of a possible relation
+}
+
+func (f *modelField) matches(name string) bool {
+	if f.tagged {
+		return f.name == name
+	}
+	return f.name == normalizeFieldName(name)
+}
+
+type modelKey struct {
+	t   reflect.Type
+	tag string
+}
+
+var modelFields sync.Map // modelKey -> []modelField
+
+// modelFieldsOf returns the fields of a model struct, which are cached per type and tag.
+func modelFieldsOf(t reflect.Type, tag string) []modelField {
+	key := modelKey{t, tag}
+	if fields, ok := modelFields.Load(key); ok {
+		return fields.([]modelField)
+	}
+	fields := appendModelFields(nil, t, tag)
+	modelFields.Store(key, fields)
+	return fields
+}
+
+func appendModelFields(dst []modelField, t reflect.Type, tag string) []modelField {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, tagged := sf.Tag.Lookup("graphql")
+		name, _, _ = strings.Cut(name, ",")
+		if name == "-" {
+			continue
+		}
+		if sf.Anonymous && !tagged && sf.Type.Kind() == reflect.Struct {
+			dst = appendModelFields(dst, sf.Type, tag)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name, tagged = normalizeFieldName(sf.Name), false
+		}
+		column, _, _ := strings.Cut(sf.Tag.Get(tag), ",")
+		if column == "-" {
+			column = ""
+		}
+		dst = append(dst, modelField{name: name, tagged: tagged, column: column, relation: relationType(sf.Type)})
+	}
+	return dst
+}
+
+// isCompositeType reports whether t is an object, interface or union type, or a list of those.
+func isCompositeType(t ast.Type) bool {
+	for {
+		switch tt := t.(type) {
+		case *ast.NonNull:
+			t = tt.OfType
+		case *ast.List:
+			t = tt.OfType
+		case *ast.ObjectTypeDefinition, *ast.InterfaceTypeDefinition, *ast.Union:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// relationType returns the struct type of a field which may be a relation, or nil.
+func relationType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// normalizeFieldName returns the name in lower case without underscores.
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
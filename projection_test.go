@@ -0,0 +1,189 @@
+package graphql_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+const projectionSchema = `
+	type Query {
+		books: [Book!]!
+		leaf: String!
+	}
+	type Book {
+		id: ID!
+		title: String!
+		pageCount: Int!
+		summary: String!
+		author: Author!
+		reviews: [Review!]!
+		publisher: Publisher!
+	}
+	type Publisher {
+		name: String!
+	}
+	type Author {
+		id: ID!
+		name: String!
+		country: Country
+	}
+	type Country {
+		code: String!
+	}
+	type Review {
+		stars: Int!
+	}
+`
+
+type projectionTimestamps struct {
+	CreatedAt string `db:"created_at"`
+}
+
+type projectionBook struct {
+	projectionTimestamps
+	ID        string              `graphql:"id" db:"id"`
+	Title     string              `graphql:"title" db:"book_title,omitempty"`
+	PageCount int32               `db:"page_count"`
+	Summary   string              `graphql:"summary" db:"-"`
+	Author    *projectionAuthor   `graphql:"author" db:"author"`
+	Reviews   []*projectionReview `graphql:"reviews"`
+	Publisher string              `graphql:"publisher" db:"publisher_id"`
+	internal  string
+}
+
+type projectionAuthor struct {
+	ID      string             `graphql:"id" db:"id"`
+	Name    string             `graphql:"name" db:"name"`
+	Country *projectionCountry `graphql:"country" db:"country"`
+}
+
+type projectionCountry struct {
+	Code string `graphql:"code" db:"code"`
+}
+
+type projectionReview struct {
+	Stars int32 `graphql:"stars" db:"stars"`
+}
+
+type projectionResolver struct {
+	projection *graphql.Projection
+	leaf       *graphql.Projection
+	err        error
+}
+
+func (r *projectionResolver) Books(ctx context.Context) []*projectionBookResolver {
+	r.projection, r.err = graphql.SelectedProjection(ctx, &projectionBook{}, "db")
+	return nil
+}
+
+func (r *projectionResolver) Leaf(ctx context.Context) string {
+	r.leaf, _ = graphql.SelectedProjection(ctx, projectionBook{}, "db")
+	return ""
+}
+
+type projectionBookResolver struct{}
+
+func (*projectionBookResolver) ID() graphql.ID                          { return "" }
+func (*projectionBookResolver) Title() string                           { return "" }
+func (*projectionBookResolver) PageCount() int32                        { return 0 }
+func (*projectionBookResolver) Summary() string                         { return "" }
+func (*projectionBookResolver) Author() *projectionAuthorResolver       { return nil }
+func (*projectionBookResolver) Reviews() []*projectionReviewResolver    { return nil }
+func (*projectionBookResolver) Publisher() *projectionPublisherResolver { return nil }
+
+type projectionAuthorResolver struct{}
+
+func (*projectionAuthorResolver) ID() graphql.ID                      { return "" }
+func (*projectionAuthorResolver) Name() string                        { return "" }
+func (*projectionAuthorResolver) Country() *projectionCountryResolver { return nil }
+
+type projectionCountryResolver struct{}
+
+func (*projectionCountryResolver) Code() string { return "" }
+
+type projectionReviewResolver struct{}
+
+func (*projectionReviewResolver) Stars() int32 { return 0 }
+
+type projectionPublisherResolver struct{}
+
+func (*projectionPublisherResolver) Name() string { return "" }
+
+func TestSelectedProjection(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "columns",
+			query: `{ books { title pageCount summary __typename } }`,
+			want:  []string{"book_title", "page_count"},
+		},
+		{
+			name:  "aliases and fragments",
+			query: `{ books { t: title ...F title } } fragment F on Book { id title }`,
+			want:  []string{"id", "book_title"},
+		},
+		{
+			name:  "nested relations",
+			query: `{ books { id author { name country { code } } reviews { stars } a: author { id } } }`,
+			want:  []string{"id", "author.id", "author.name", "author.country.code", "reviews.stars"},
+		},
+		{
+			name:  "relation without columns",
+			query: `{ books { author { __typename } } }`,
+			want:  []string{},
+		},
+		{
+			name:  "foreign key",
+			query: `{ books { publisher { name } } }`,
+			want:  []string{"publisher_id"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &projectionResolver{}
+			s := graphql.MustParseSchema(projectionSchema, r)
+			res := s.Exec(context.Background(), tc.query, "", nil)
+			if len(res.Errors) != 0 {
+				t.Fatal(res.Errors)
+			}
+			if r.err != nil {
+				t.Fatal(r.err)
+			}
+			if got := r.projection.Qualified(); !slices.Equal(got, tc.want) {
+				t.Errorf("got columns %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectedProjectionRelations(t *testing.T) {
+	r := &projectionResolver{}
+	s := graphql.MustParseSchema(projectionSchema, r)
+	s.Exec(context.Background(), `{ books { createdAt: id author { name } } leaf }`, "", nil)
+
+	p := r.projection
+	if !slices.Equal(p.Columns, []string{"id"}) {
+		t.Errorf("got columns %q", p.Columns)
+	}
+	if author := p.Relations["author"]; author == nil || !slices.Equal(author.Columns, []string{"name"}) {
+		t.Errorf("got author projection %+v", author)
+	}
+	if len(p.Relations) != 1 {
+		t.Errorf("got relations %v", p.Relations)
+	}
+	if r.leaf == nil || len(r.leaf.Qualified()) != 0 {
+		t.Errorf("got projection of a leaf field %+v", r.leaf)
+	}
+}
+
+func TestSelectedProjectionInvalidModel(t *testing.T) {
+	_, err := graphql.SelectedProjection(context.Background(), "book", "db")
+	if err == nil || err.Error() != "graphql: projection model must be a struct, got string" {
+		t.Errorf("got error %v", err)
+	}
+}
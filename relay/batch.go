@@ -0,0 +1,74 @@
+package relay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+const (
+	defaultMaxBatchSize        = 100
+	defaultMaxBatchConcurrency = 10
+)
+
+// maxBatchSize returns the maximum number of operations in a batched request.
+func (h *Handler) maxBatchSize() int {
+	if h.MaxBatchSize <= 0 {
+		return defaultMaxBatchSize
+	}
+	return h.MaxBatchSize
+}
+
+// decodeParams decodes a single operation or, if data is a JSON array, a batch of operations.
+func decodeParams(data []byte) (ps []*graphql.Request, batch bool, err error) {
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.NewDecoder(bytes.NewReader(data)).Decode(&ps); err != nil {
+			return nil, false, err
+		}
+		for i, p := range ps {
+			if p == nil {
+				ps[i] = &graphql.Request{}
+			}
+		}
+		return ps, true, nil
+	}
+	var p graphql.Request
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&p); err != nil {
+		return nil, false, err
+	}
+	return []*graphql.Request{&p}, false, nil
+}
+
+// execBatch executes the operations of a batched request and returns their responses in the
+// order of the operations. At most MaxBatchConcurrency operations are executed at once.
+func (h *Handler) execBatch(ctx context.Context, ps []*graphql.Request) []*graphql.Response {
+	responses := make([]*graphql.Response, len(ps))
+	limit := h.MaxBatchConcurrency
+	if limit <= 0 {
+		limit = defaultMaxBatchConcurrency
+	}
+	limit = min(limit, len(ps))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, p := range ps {
+		if !hasDocument(p) {
+			responses[i] = &graphql.Response{Errors: []*qerrors.QueryError{qerrors.Errorf("query is missing")}}
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			responses[i] = h.Schema.ExecRequest(ctx, p)
+		}()
+	}
+	wg.Wait()
+	return responses
+}
@@ -0,0 +1,144 @@
+package relay_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+func TestServeHTTPBatch(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		handler    *relay.Handler
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:    "ordered responses",
+			handler: &relay.Handler{Schema: starwarsSchema},
+			body: `[
+				{"query":"query($id: ID!) { character(id: $id) { name } }","variables":{"id":"1000"}},
+				{"query":"{ hero { name } }"},
+				{"query":"query A { hero { id } } query B { hero { name } }","operationName":"B"}
+			]`,
+			wantStatus: http.StatusOK,
+			wantBody:   `[{"data":{"character":{"name":"Luke Skywalker"}}},{"data":{"hero":{"name":"R2-D2"}}},{"data":{"hero":{"name":"R2-D2"}}}]`,
+		},
+		{
+			name:       "failing operation",
+			handler:    &relay.Handler{Schema: starwarsSchema},
+			body:       `[{"query":"{ hero { name } }"},{"query":"{ unknown }"},{}]`,
+			wantStatus: http.StatusOK,
+			wantBody:   `[{"data":{"hero":{"name":"R2-D2"}}},{"errors":[{"message":"Cannot query field \"unknown\" on type \"Query\".","locations":[{"line":1,"column":3}]}]},{"errors":[{"message":"query is missing"}]}]`,
+		},
+		{
+			name:       "empty batch",
+			handler:    &relay.Handler{Schema: starwarsSchema},
+			body:       `[]`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"errors":[{"message":"batch is empty"}]}`,
+		},
+		{
+			name:       "default batch size",
+			handler:    &relay.Handler{Schema: starwarsSchema},
+			body:       "[" + strings.Repeat(`{"query":"{ hero { name } }"},`, 100) + `{"query":"{ hero { name } }"}]`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"errors":[{"message":"batch of 101 operations exceeds the maximum of 100 operations"}]}`,
+		},
+		{
+			name:       "batch too large",
+			handler:    &relay.Handler{Schema: starwarsSchema, MaxBatchSize: 1},
+			body:       `[{"query":"{ hero { name } }"},{"query":"{ hero { name } }"}]`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"errors":[{"message":"batch of 2 operations exceeds the maximum of 1 operations"}]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", "application/json")
+			tc.handler.ServeHTTP(w, r)
+			if w.Code != tc.wantStatus {
+				t.Errorf("expected status %d, got %d", tc.wantStatus, w.Code)
+			}
+			if got := w.Body.String(); got != tc.wantBody {
+				t.Errorf("unexpected body:\n got: %s\nwant: %s", got, tc.wantBody)
+			}
+		})
+	}
+}
+
+type concurrencyResolver struct {
+	running, max atomic.Int32
+}
+
+func (r *concurrencyResolver) Wait() int32 {
+	n := r.running.Add(1)
+	defer r.running.Add(-1)
+	for {
+		m := r.max.Load()
+		if n <= m || r.max.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return n
+}
+
+func TestServeHTTPBatchConcurrency(t *testing.T) {
+	res := &concurrencyResolver{}
+	schema := graphql.MustParseSchema(`type Query { wait: Int! }`, res)
+	h := &relay.Handler{Schema: schema, MaxBatchConcurrency: 2}
+
+	body := "[" + strings.Repeat(`{"query":"{ wait }"},`, 5) + `{"query":"{ wait }"}]`
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body)
+	}
+	if got := res.max.Load(); got != 2 {
+		t.Errorf("expected 2 concurrent operations, got %d", got)
+	}
+}
+
+func TestServeHTTPBatchDefaultConcurrency(t *testing.T) {
+	res := &concurrencyResolver{}
+	schema := graphql.MustParseSchema(`type Query { wait: Int! }`, res)
+	h := &relay.Handler{Schema: schema}
+
+	body := "[" + strings.Repeat(`{"query":"{ wait }"},`, 19) + `{"query":"{ wait }"}]`
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body)
+	}
+	if got := res.max.Load(); got > 10 {
+		t.Errorf("expected at most 10 concurrent operations, got %d", got)
+	}
+}
+
+func TestServeHTTPBatchUpload(t *testing.T) {
+	schema := graphql.MustParseSchema(uploadSchema, &uploadResolver{})
+	h := &relay.Handler{Schema: schema}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, multipartRequest(t,
+		`[{"query":"mutation($file: Upload!) { upload(file: $file) }","variables":{"file":null}},{"query":"mutation($file: Upload!) { upload(file: $file) }","variables":{"file":null}}]`,
+		`{"0":["0.variables.file"],"1":["1.variables.file"]}`,
+		uploadFile{"0", "a.txt", "Alpha"},
+		uploadFile{"1", "b.txt", "Beta"},
+	))
+	want := `[{"data":{"upload":"a.txt (text/plain, 5 bytes): Alpha"}},{"data":{"upload":"b.txt (text/plain, 4 bytes): Beta"}}]`
+	if got := w.Body.String(); got != want {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", got, want)
+	}
+}
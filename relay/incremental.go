@@ -0,0 +1,69 @@
+package relay
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const mediaTypeMultipartMixed = "multipart/mixed"
+
+// acceptsIncremental reports whether the Accept header values allow responses to be delivered
+// incrementally as multipart/mixed.
+func acceptsIncremental(accept []string) bool {
+	for _, header := range accept {
+		for mr := range strings.SplitSeq(header, ",") {
+			mt, mtParams, err := mime.ParseMediaType(strings.TrimSpace(mr))
+			if err == nil && mt == mediaTypeMultipartMixed && mtParams["q"] != "0" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// initialResponse is the first part of an incremental response.
+type initialResponse struct {
+	*graphql.Response
+	HasNext bool `json:"hasNext"`
+}
+
+// writeIncremental writes the initial response and the subsequent responses as the parts of a
+// multipart/mixed response, flushing each part as soon as it has been written.
+//
+// See https://github.com/graphql/graphql-over-http/blob/main/rfcs/IncrementalDelivery.md
+func writeIncremental(w http.ResponseWriter, initial *graphql.Response, subsequent <-chan *graphql.SubsequentResponse) {
+	w.Header().Set("Content-Type", `multipart/mixed; boundary="-"; deferSpec=20220824`)
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	writePart := func(v any) bool {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return false
+		}
+		if _, err := w.Write([]byte("\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n")); err != nil {
+			return false
+		}
+		if _, err := w.Write(data); err != nil {
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return true
+	}
+
+	ok := writePart(&initialResponse{Response: initial, HasNext: true})
+	for resp := range subsequent {
+		if ok {
+			ok = writePart(resp)
+		}
+	}
+	if ok {
+		w.Write([]byte("\r\n-----\r\n"))
+	}
+}
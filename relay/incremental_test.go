@@ -0,0 +1,70 @@
+package relay_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+func TestServeHTTPIncremental(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.IncrementalDelivery())
+
+	for _, tc := range []struct {
+		name     string
+		accept   string
+		body     string
+		wantType string
+		wantBody string
+	}{
+		{
+			name:     "defer",
+			accept:   "multipart/mixed, application/json",
+			body:     `{"query":"{ hero { id ... @defer(label: \"details\") { name } } }"}`,
+			wantType: `multipart/mixed; boundary="-"; deferSpec=20220824`,
+			wantBody: "\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n" +
+				`{"data":{"hero":{"id":"2001"}},"hasNext":true}` +
+				"\r\n---\r\nContent-Type: application/json; charset=utf-8\r\n\r\n" +
+				`{"incremental":[{"data":{"name":"R2-D2"},"path":["hero"],"label":"details"}],"hasNext":false}` +
+				"\r\n-----\r\n",
+		},
+		{
+			name:     "nothing deferred",
+			accept:   "multipart/mixed",
+			body:     `{"query":"{ hero { name } }"}`,
+			wantType: "application/json",
+			wantBody: `{"data":{"hero":{"name":"R2-D2"}}}`,
+		},
+		{
+			name:     "not accepted",
+			accept:   "application/json",
+			body:     `{"query":"{ hero { id ... @defer { name } } }"}`,
+			wantType: "application/json",
+			wantBody: `{"data":{"hero":{"id":"2001","name":"R2-D2"}}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("Accept", tc.accept)
+			h := relay.Handler{Schema: schema}
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("want status %d, got %d", http.StatusOK, w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); got != tc.wantType {
+				t.Errorf("want content type %q, got %q", tc.wantType, got)
+			}
+			if got := w.Body.String(); got != tc.wantBody {
+				t.Errorf("wrong body\nwant: %q\ngot:  %q", tc.wantBody, got)
+			}
+		})
+	}
+}
@@ -1,14 +1,21 @@
 package relay
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
 	graphql "github.com/graph-gophers/graphql-go"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/query"
 )
 
 func MarshalID(kind string, spec any) graphql.ID {
@@ -43,28 +50,249 @@ func UnmarshalSpec(id graphql.ID, v any) error {
 	return json.Unmarshal(s[i+1:], v)
 }
 
+// paramsFromQuery reads the request parameters from the query string of a GET request.
+func paramsFromQuery(q url.Values) (*graphql.Request, error) {
+	p := &graphql.Request{
+		Query:         q.Get("query"),
+		OperationName: q.Get("operationName"),
+		DocumentID:    q.Get("documentId"),
+	}
+	for name, dst := range map[string]*map[string]any{"variables": &p.Variables, "extensions": &p.Extensions} {
+		if v := q.Get(name); v != "" {
+			if err := json.Unmarshal([]byte(v), dst); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+		}
+	}
+	return p, nil
+}
+
+// hasDocument reports whether the request sends a query or references a stored document.
+func hasDocument(p *graphql.Request) bool {
+	return p.Query != "" || p.DocumentID != "" || p.Extensions["persistedQuery"] != nil
+}
+
+// isSubscription reports whether the operation of the request is a subscription. Requests whose
+// operation type is unknown are not, so that the error is reported by [graphql.Schema.ExecRequest].
+func isSubscription(ctx context.Context, s *graphql.Schema, p *graphql.Request) bool {
+	t, _ := s.OperationType(ctx, p)
+	return t == query.Subscription
+}
+
+// Media types used by [Handler].
+const (
+	mediaTypeJSON            = "application/json"
+	mediaTypeGraphQLResponse = "application/graphql-response+json"
+	mediaTypeGraphQL         = "application/graphql"
+)
+
+// Handler serves GraphQL queries and mutations over HTTP following the GraphQL-over-HTTP
+// specification (https://graphql.github.io/graphql-over-http/draft/).
+//
+// Requests are accepted as GET requests with the parameters in the query string, or as POST
+// requests with an application/json or application/graphql body. Mutations are rejected over
+// GET with status 405. The response media type is negotiated from the Accept header:
+// application/graphql-response+json responds with status 400 to requests that fail before
+// execution (e.g. parse or validation errors), while application/json, which is used when the
+// client does not send an Accept header, always responds with status 200 to well-formed requests.
+//
+// File uploads are supported with multipart/form-data requests following the GraphQL multipart
+// request specification (https://github.com/jaydenseric/graphql-multipart-request-spec). The
+// uploaded files are bound to the referenced variables as [graphql.Upload] values. Browsers send
+// multipart requests cross-origin without a CORS preflight, so handlers accepting uploads should
+// be protected against cross-site request forgery.
+//
+// Automatic persisted queries and trusted documents are supported if the schema was created with
+// [graphql.AutomaticPersistedQueries] or [graphql.TrustedDocuments]. Stored documents may also be
+// looked up by their hash or ID in GET requests, unless they are mutations.
+//
+// POST requests whose JSON body (or multipart operations field) is an array are executed as a
+// batch. The operations of a batch run concurrently and the response is an array with the result
+// of each operation in the order of the request.
+//
+// If the schema enables [graphql.IncrementalDelivery], clients accepting multipart/mixed responses
+// receive the results of fragments marked with @defer and list items of fields marked with @stream
+// incrementally, following the incremental delivery RFC (https://github.com/graphql/graphql-over-http/blob/main/rfcs/IncrementalDelivery.md).
+// Batched operations are not delivered incrementally.
 type Handler struct {
 	Schema *graphql.Schema
+
+	// MaxUploadSize limits the total size in bytes of multipart requests. The default is 32MB.
+	MaxUploadSize int64
+
+	// MaxUploadFiles limits the number of files in a multipart request. Requests are rejected as
+	// soon as a file exceeding the limit is encountered. The default is 0 which disables the limit.
+	MaxUploadFiles int
+
+	// MaxBatchSize limits the number of operations in a batched request. The default is 100.
+	MaxBatchSize int
+
+	// MaxBatchConcurrency limits the number of operations of a batched request that are executed
+	// concurrently. The default is 10.
+	MaxBatchConcurrency int
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	var params struct {
-		Query         string         `json:"query"`
-		OperationName string         `json:"operationName"`
-		Variables     map[string]any `json:"variables"`
+	mediaType, ok := negotiate(r.Header.Values("Accept"))
+	if !ok {
+		writeErrors(w, mediaTypeJSON, http.StatusNotAcceptable, "none of the accepted media types are supported, use %s or %s", mediaTypeGraphQLResponse, mediaTypeJSON)
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+
+	var ps []*graphql.Request
+	var batch bool
+	switch r.Method {
+	case http.MethodGet:
+		p, err := paramsFromQuery(r.URL.Query())
+		if err != nil {
+			writeErrors(w, mediaType, http.StatusBadRequest, "%s", err)
+			return
+		}
+		if t, _ := h.Schema.OperationType(r.Context(), p); t == query.Mutation {
+			w.Header().Set("Allow", http.MethodPost)
+			writeErrors(w, mediaType, http.StatusMethodNotAllowed, "mutations can only be executed using POST requests")
+			return
+		}
+		ps = []*graphql.Request{p}
+
+	case http.MethodPost:
+		ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch ct {
+		case mediaTypeJSON, "":
+			body, err := io.ReadAll(r.Body)
+			if err == nil {
+				ps, batch, err = decodeParams(body)
+			}
+			if err != nil {
+				writeErrors(w, mediaType, http.StatusBadRequest, "invalid request body: %s", err)
+				return
+			}
+		case mediaTypeGraphQL:
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeErrors(w, mediaType, http.StatusBadRequest, "invalid request body: %s", err)
+				return
+			}
+			p, err := paramsFromQuery(r.URL.Query())
+			if err != nil {
+				writeErrors(w, mediaType, http.StatusBadRequest, "%s", err)
+				return
+			}
+			p.Query = string(body)
+			ps = []*graphql.Request{p}
+		case mediaTypeMultipart:
+			var cleanup func()
+			var err error
+			if ps, batch, cleanup, err = h.readMultipart(w, r); err != nil {
+				status := http.StatusBadRequest
+				if err == errUploadTooLarge {
+					status = http.StatusRequestEntityTooLarge
+				}
+				writeErrors(w, mediaType, status, "%s", err)
+				return
+			}
+			defer cleanup()
+		default:
+			writeErrors(w, mediaType, http.StatusUnsupportedMediaType, "unsupported content type %q", ct)
+			return
+		}
+
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeErrors(w, mediaType, http.StatusMethodNotAllowed, "method %s is not allowed", r.Method)
 		return
 	}
 
-	response := h.Schema.Exec(r.Context(), params.Query, params.OperationName, params.Variables)
-	responseJSON, err := json.Marshal(response)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if batch {
+		switch {
+		case len(ps) == 0:
+			writeErrors(w, mediaType, http.StatusBadRequest, "batch is empty")
+		case len(ps) > h.maxBatchSize():
+			writeErrors(w, mediaType, http.StatusBadRequest, "batch of %d operations exceeds the maximum of %d operations", len(ps), h.maxBatchSize())
+		default:
+			writeJSON(w, mediaType, http.StatusOK, h.execBatch(r.Context(), ps))
+		}
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(responseJSON)
+	p := ps[0]
+	if !hasDocument(p) {
+		writeErrors(w, mediaType, http.StatusBadRequest, "query is missing")
+		return
+	}
+
+	if acceptsIncremental(r.Header.Values("Accept")) {
+		response, subsequent := h.Schema.ExecIncremental(r.Context(), p)
+		if subsequent != nil {
+			writeIncremental(w, response, subsequent)
+			return
+		}
+		writeResponse(w, mediaType, response)
+		return
+	}
+	writeResponse(w, mediaType, h.Schema.ExecRequest(r.Context(), p))
+}
+
+// writeResponse writes the response of a single operation.
+func writeResponse(w http.ResponseWriter, mediaType string, response *graphql.Response) {
+	status := http.StatusOK
+	if mediaType == mediaTypeGraphQLResponse && response.Data == nil {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, mediaType, status, response)
+}
+
+// negotiate selects the response media type from the Accept header values. Clients which do not
+// send an Accept header receive application/json.
+func negotiate(accept []string) (string, bool) {
+	if len(accept) == 0 {
+		return mediaTypeJSON, true
+	}
+
+	best, bestQ := "", 0.0
+	for _, header := range accept {
+		for mr := range strings.SplitSeq(header, ",") {
+			mt, mtParams, err := mime.ParseMediaType(strings.TrimSpace(mr))
+			if err != nil {
+				continue
+			}
+			q := 1.0
+			if v, ok := mtParams["q"]; ok {
+				if q, err = strconv.ParseFloat(v, 64); err != nil {
+					continue
+				}
+			}
+
+			var candidate string
+			switch mt {
+			case mediaTypeGraphQLResponse, "application/*", "*/*":
+				candidate = mediaTypeGraphQLResponse
+			case mediaTypeJSON, mediaTypeMultipartMixed:
+				// Responses which are not delivered incrementally use application/json.
+				candidate = mediaTypeJSON
+			default:
+				continue
+			}
+			// On equal preference, application/graphql-response+json wins.
+			if q > bestQ || (q == bestQ && candidate == mediaTypeGraphQLResponse) {
+				best, bestQ = candidate, q
+			}
+		}
+	}
+	return best, best != ""
+}
+
+func writeErrors(w http.ResponseWriter, mediaType string, status int, format string, a ...any) {
+	writeJSON(w, mediaType, status, &graphql.Response{Errors: []*qerrors.QueryError{qerrors.Errorf(format, a...)}})
+}
+
+func writeJSON(w http.ResponseWriter, mediaType string, status int, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(&graphql.Response{Errors: []*qerrors.QueryError{qerrors.Errorf("%s", err)}})
+		status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(status)
+	w.Write(data)
 }
@@ -1,7 +1,9 @@
 package relay_test
 
 import (
+	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -34,3 +36,225 @@ func TestServeHTTP(t *testing.T) {
 		t.Fatalf("Invalid response. Expected [%s], but instead got [%s]", expectedResponse, actualResponse)
 	}
 }
+
+func TestServeHTTPGraphQLOverHTTP(t *testing.T) {
+	h := relay.Handler{Schema: starwarsSchema}
+
+	for _, tc := range []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		accept      string
+		body        string
+		wantStatus  int
+		wantType    string
+		wantBody    string
+	}{
+		{
+			name:       "GET query",
+			method:     http.MethodGet,
+			target:     "/graphql?query=" + url.QueryEscape(`query($id: ID!) { character(id: $id) { name } }`) + "&variables=" + url.QueryEscape(`{"id":"1000"}`),
+			wantStatus: http.StatusOK,
+			wantType:   "application/json",
+			wantBody:   `{"data":{"character":{"name":"Luke Skywalker"}}}`,
+		},
+		{
+			name:       "GET mutation is rejected",
+			method:     http.MethodGet,
+			target:     "/graphql?query=" + url.QueryEscape(`mutation { createReview(episode: JEDI, review: {stars: 5}) { stars } }`),
+			accept:     "application/graphql-response+json",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantType:   "application/graphql-response+json",
+			wantBody:   `{"errors":[{"message":"mutations can only be executed using POST requests"}]}`,
+		},
+		{
+			name:        "POST application/graphql",
+			method:      http.MethodPost,
+			target:      "/graphql",
+			contentType: "application/graphql",
+			accept:      "application/graphql-response+json",
+			body:        `{ hero { name } }`,
+			wantStatus:  http.StatusOK,
+			wantType:    "application/graphql-response+json",
+			wantBody:    `{"data":{"hero":{"name":"R2-D2"}}}`,
+		},
+		{
+			name:        "validation error with graphql-response+json",
+			method:      http.MethodPost,
+			target:      "/graphql",
+			contentType: "application/json",
+			accept:      "application/graphql-response+json, application/json;q=0.9",
+			body:        `{"query":"{ unknown }"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantType:    "application/graphql-response+json",
+			wantBody:    `{"errors":[{"message":"Cannot query field \"unknown\" on type \"Query\".","locations":[{"line":1,"column":3}]}]}`,
+		},
+		{
+			name:        "validation error with application/json",
+			method:      http.MethodPost,
+			target:      "/graphql",
+			contentType: "application/json",
+			accept:      "application/json",
+			body:        `{"query":"{ unknown }"}`,
+			wantStatus:  http.StatusOK,
+			wantType:    "application/json",
+			wantBody:    `{"errors":[{"message":"Cannot query field \"unknown\" on type \"Query\".","locations":[{"line":1,"column":3}]}]}`,
+		},
+		{
+			name:        "execution error with graphql-response+json",
+			method:      http.MethodPost,
+			target:      "/graphql",
+			contentType: "application/json",
+			accept:      "*/*",
+			body:        `{"query":"{ character(id: \"unknown\") { name } }"}`,
+			wantStatus:  http.StatusOK,
+			wantType:    "application/graphql-response+json",
+			wantBody:    `{"data":{"character":null}}`,
+		},
+		{
+			name:        "invalid JSON body",
+			method:      http.MethodPost,
+			target:      "/graphql",
+			contentType: "application/json",
+			body:        `{`,
+			wantStatus:  http.StatusBadRequest,
+			wantType:    "application/json",
+			wantBody:    `{"errors":[{"message":"invalid request body: unexpected EOF"}]}`,
+		},
+		{
+			name:        "missing query",
+			method:      http.MethodPost,
+			target:      "/graphql",
+			contentType: "application/json",
+			body:        `{}`,
+			wantStatus:  http.StatusBadRequest,
+			wantType:    "application/json",
+			wantBody:    `{"errors":[{"message":"query is missing"}]}`,
+		},
+		{
+			name:        "unsupported content type",
+			method:      http.MethodPost,
+			target:      "/graphql",
+			contentType: "text/plain",
+			body:        `{ hero { name } }`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantType:    "application/json",
+			wantBody:    `{"errors":[{"message":"unsupported content type \"text/plain\""}]}`,
+		},
+		{
+			name:       "not acceptable",
+			method:     http.MethodGet,
+			target:     "/graphql?query=" + url.QueryEscape(`{ hero { name } }`),
+			accept:     "text/html",
+			wantStatus: http.StatusNotAcceptable,
+			wantType:   "application/json",
+			wantBody:   `{"errors":[{"message":"none of the accepted media types are supported, use application/graphql-response+json or application/json"}]}`,
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodPut,
+			target:     "/graphql",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantType:   "application/json",
+			wantBody:   `{"errors":[{"message":"method PUT is not allowed"}]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				r.Header.Set("Content-Type", tc.contentType)
+			}
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.wantStatus {
+				t.Errorf("expected status %d, got %d", tc.wantStatus, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tc.wantType {
+				t.Errorf("expected content type %q, got %q", tc.wantType, ct)
+			}
+			if got := w.Body.String(); got != tc.wantBody {
+				t.Errorf("unexpected body:\n got: %s\nwant: %s", got, tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestServeHTTPPersistedQueries(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.AutomaticPersistedQueries(graphql.NewPersistedQueryCache(10)))
+	h := &relay.Handler{Schema: schema}
+
+	// sha256("{ hero { name } }")
+	const ext = `{"persistedQuery":{"version":1,"sha256Hash":"aae585680c3470e4947255eafbd1eafe87d1c3f129259cf15e404d1bb7f1e8f4"}}`
+	// sha256("mutation { createReview(episode: JEDI, review: {stars: 5}) { stars } }")
+	const mutationExt = `{"persistedQuery":{"version":1,"sha256Hash":"49318de0ee774d2a1b51e7747d83dbbf483a9c6804007fcf2023b7ddd154561e"}}`
+	hashOnly := `{"extensions":` + ext + `}`
+	for _, tc := range []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "unknown hash",
+			method:     http.MethodPost,
+			body:       hashOnly,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`,
+		},
+		{
+			name:       "register",
+			method:     http.MethodPost,
+			body:       `{"query":"{ hero { name } }","extensions":` + ext + `}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"data":{"hero":{"name":"R2-D2"}}}`,
+		},
+		{
+			name:       "lookup",
+			method:     http.MethodPost,
+			body:       hashOnly,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"data":{"hero":{"name":"R2-D2"}}}`,
+		},
+		{
+			name:       "lookup over GET",
+			method:     http.MethodGet,
+			target:     "?extensions=" + url.QueryEscape(ext),
+			wantStatus: http.StatusOK,
+			wantBody:   `{"data":{"hero":{"name":"R2-D2"}}}`,
+		},
+		{
+			name:       "register mutation",
+			method:     http.MethodPost,
+			body:       `{"query":"mutation { createReview(episode: JEDI, review: {stars: 5}) { stars } }","extensions":` + mutationExt + `}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"data":{"createReview":{"stars":5}}}`,
+		},
+		{
+			name:       "mutation lookup over GET",
+			method:     http.MethodGet,
+			target:     "?extensions=" + url.QueryEscape(mutationExt),
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   `{"errors":[{"message":"mutations can only be executed using POST requests"}]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "/graphql"+tc.target, strings.NewReader(tc.body))
+			h.ServeHTTP(w, r)
+			if w.Code != tc.wantStatus {
+				t.Errorf("expected status %d, got %d", tc.wantStatus, w.Code)
+			}
+			if got := w.Body.String(); got != tc.wantBody {
+				t.Errorf("unexpected body:\n got: %s\nwant: %s", got, tc.wantBody)
+			}
+		})
+	}
+}
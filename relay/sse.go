@@ -0,0 +1,430 @@
+package relay
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/query"
+)
+
+// SSETokenHeader is the header used to identify a reserved event stream in the
+// "single connection" mode of the graphql-sse protocol.
+const SSETokenHeader = "X-GraphQL-Event-Stream-Token"
+
+const (
+	defaultSSEKeepAlive          = 12 * time.Second
+	defaultSSEReservationTimeout = 30 * time.Second
+	defaultSSEMaxReservations    = 1000
+)
+
+// SSEHandler streams GraphQL results as Server-Sent Events following the graphql-sse protocol.
+//
+// In the "distinct connections" mode each GET or POST request carries a single operation and
+// receives its results as next events followed by a complete event on the same response.
+//
+// In the "single connection" mode the client reserves a stream with a PUT request, opens it with a
+// GET request carrying the returned token and then starts (POST) and stops (DELETE) operations,
+// whose results are multiplexed over the reserved stream. Operations can only be started once the
+// stream is open, and reserved streams which are not opened in time are released.
+//
+// Queries and mutations are executed with [graphql.Schema.ExecRequest] and subscriptions with
+// [graphql.Schema.SubscribeRequest]. Every event payload is a [graphql.Response].
+//
+// See https://github.com/enisdenjo/graphql-sse/blob/master/PROTOCOL.md
+type SSEHandler struct {
+	Schema *graphql.Schema
+
+	// KeepAlive is the interval at which comment lines are written to open streams to keep
+	// proxies from closing idle connections. The default is 12 seconds; a negative value
+	// disables keepalives.
+	KeepAlive time.Duration
+
+	// ReservationTimeout is the duration after which a stream reserved in single connection
+	// mode is released if it was not opened. The default is 30 seconds.
+	ReservationTimeout time.Duration
+
+	// MaxReservations limits the number of streams reserved in single connection mode which
+	// were not opened yet. Further reservations are rejected with 503 Service Unavailable. The
+	// default is 1000.
+	MaxReservations int
+
+	mu       sync.Mutex
+	streams  map[string]*sseStream
+	reserved int // number of streams which were not opened yet
+}
+
+type sseEvent struct {
+	name string
+	data []byte
+}
+
+// sseStream is a stream reserved in single connection mode.
+type sseStream struct {
+	events chan sseEvent
+	done   chan struct{}
+
+	mu        sync.Mutex
+	connected bool
+	expired   bool
+	expiry    *time.Timer
+	ops       map[string]context.CancelFunc
+}
+
+func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get(SSETokenHeader)
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+
+	switch {
+	case r.Method == http.MethodPut:
+		h.reserve(w)
+	case token != "":
+		h.serveSingle(w, r, token)
+	case r.Method == http.MethodGet || r.Method == http.MethodPost:
+		h.serveDistinct(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, PUT, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// serveDistinct runs a single operation and streams its results on the response.
+func (h *SSEHandler) serveDistinct(w http.ResponseWriter, r *http.Request) {
+	p, err := readSSEParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.allowMethod(w, r, p) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	events := make(chan sseEvent)
+	go func() {
+		defer close(events)
+		h.execute(ctx, p, func(resp *graphql.Response) bool {
+			data, err := json.Marshal(resp)
+			if err != nil {
+				return false
+			}
+			select {
+			case events <- sseEvent{name: "next", data: data}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	h.stream(w, r, events)
+	if ctx.Err() == nil {
+		_ = writeSSEEvent(w, sseEvent{name: "complete"})
+		_ = http.NewResponseController(w).Flush()
+	}
+}
+
+// reserve creates a new single connection mode stream and responds with its token.
+func (h *SSEHandler) reserve(w http.ResponseWriter) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	token := hex.EncodeToString(b[:])
+
+	maxReservations := h.MaxReservations
+	if maxReservations <= 0 {
+		maxReservations = defaultSSEMaxReservations
+	}
+	timeout := h.ReservationTimeout
+	if timeout <= 0 {
+		timeout = defaultSSEReservationTimeout
+	}
+
+	h.mu.Lock()
+	if h.reserved >= maxReservations {
+		h.mu.Unlock()
+		http.Error(w, "too many reserved streams", http.StatusServiceUnavailable)
+		return
+	}
+	if h.streams == nil {
+		h.streams = make(map[string]*sseStream)
+	}
+	s := &sseStream{
+		events: make(chan sseEvent, 16),
+		done:   make(chan struct{}),
+		ops:    make(map[string]context.CancelFunc),
+	}
+	s.expiry = time.AfterFunc(timeout, func() { h.expire(token, s) })
+	h.streams[token] = s
+	h.reserved++
+	h.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	io.WriteString(w, token)
+}
+
+func (h *SSEHandler) serveSingle(w http.ResponseWriter, r *http.Request, token string) {
+	h.mu.Lock()
+	s := h.streams[token]
+	h.mu.Unlock()
+	if s == nil {
+		http.Error(w, "stream not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.Lock()
+		connected, expired := s.connected, s.expired
+		if !connected && !expired {
+			s.connected = true
+			s.expiry.Stop()
+		}
+		s.mu.Unlock()
+		if expired {
+			http.Error(w, "stream not found", http.StatusNotFound)
+			return
+		}
+		if connected {
+			http.Error(w, "stream already open", http.StatusConflict)
+			return
+		}
+		h.mu.Lock()
+		h.reserved--
+		h.mu.Unlock()
+		defer h.release(token, s)
+		h.stream(w, r, s.events)
+
+	case http.MethodPost:
+		p, err := readSSEParams(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !h.allowMethod(w, r, p) {
+			return
+		}
+		id, _ := p.Extensions["operationId"].(string)
+		if id == "" {
+			http.Error(w, "operation id is missing", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+		s.mu.Lock()
+		if !s.connected || s.closed() {
+			s.mu.Unlock()
+			cancel()
+			http.Error(w, "stream is not open", http.StatusConflict)
+			return
+		}
+		if _, exists := s.ops[id]; exists {
+			s.mu.Unlock()
+			cancel()
+			http.Error(w, fmt.Sprintf("operation with id %q already exists", id), http.StatusConflict)
+			return
+		}
+		s.ops[id] = cancel
+		s.mu.Unlock()
+
+		go func() {
+			defer cancel()
+			h.execute(ctx, p, func(resp *graphql.Response) bool {
+				data, err := json.Marshal(struct {
+					ID      string            `json:"id"`
+					Payload *graphql.Response `json:"payload"`
+				}{id, resp})
+				if err != nil {
+					return false
+				}
+				return s.send(ctx, sseEvent{name: "next", data: data})
+			})
+			if s.take(id) != nil {
+				data, _ := json.Marshal(map[string]string{"id": id})
+				s.send(ctx, sseEvent{name: "complete", data: data})
+			}
+		}()
+		w.WriteHeader(http.StatusAccepted)
+
+	case http.MethodDelete:
+		if cancel := s.take(r.URL.Query().Get("operationId")); cancel != nil {
+			cancel()
+		}
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		w.Header().Set("Allow", "GET, POST, PUT, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// expire releases a reserved stream which was not opened in time.
+func (h *SSEHandler) expire(token string, s *sseStream) {
+	s.mu.Lock()
+	if s.connected {
+		s.mu.Unlock()
+		return
+	}
+	s.expired = true
+	s.mu.Unlock()
+
+	h.mu.Lock()
+	delete(h.streams, token)
+	h.reserved--
+	h.mu.Unlock()
+}
+
+// release stops all operations of a stream and forgets its token.
+func (h *SSEHandler) release(token string, s *sseStream) {
+	h.mu.Lock()
+	delete(h.streams, token)
+	h.mu.Unlock()
+
+	close(s.done)
+	s.mu.Lock()
+	for id, cancel := range s.ops {
+		cancel()
+		delete(s.ops, id)
+	}
+	s.mu.Unlock()
+}
+
+// closed reports whether the stream was released.
+func (s *sseStream) closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *sseStream) send(ctx context.Context, e sseEvent) bool {
+	select {
+	case s.events <- e:
+		return true
+	case <-s.done:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// take unregisters the operation with the given id and returns its cancel function,
+// or nil if the operation is not running anymore.
+func (s *sseStream) take(id string) context.CancelFunc {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cancel := s.ops[id]
+	delete(s.ops, id)
+	return cancel
+}
+
+// execute runs the operation and passes each result to emit until emit returns false.
+func (h *SSEHandler) execute(ctx context.Context, p *graphql.Request, emit func(*graphql.Response) bool) {
+	if !isSubscription(ctx, h.Schema, p) {
+		emit(h.Schema.ExecRequest(ctx, p))
+		return
+	}
+
+	responses, err := h.Schema.SubscribeRequest(ctx, p)
+	if err != nil {
+		emit(&graphql.Response{Errors: []*errors.QueryError{errors.Errorf("%s", err)}})
+		return
+	}
+	for resp := range responses {
+		if !emit(resp.(*graphql.Response)) {
+			return
+		}
+	}
+}
+
+// stream writes events to w until the events channel is closed or the request is cancelled.
+func (h *SSEHandler) stream(w http.ResponseWriter, r *http.Request, events <-chan sseEvent) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	var keepAlive <-chan time.Time
+	interval := h.KeepAlive
+	if interval == 0 {
+		interval = defaultSSEKeepAlive
+	}
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		keepAlive = ticker.C
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive:
+			if _, err := io.WriteString(w, ":\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeSSEEvent(w, e); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeSSEEvent(w io.Writer, e sseEvent) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.name, e.data)
+	return err
+}
+
+// allowMethod rejects mutations sent in GET requests, which could be issued cross-site, like
+// [Handler] does. It reports whether the operation may be executed.
+func (h *SSEHandler) allowMethod(w http.ResponseWriter, r *http.Request, p *graphql.Request) bool {
+	if r.Method != http.MethodGet {
+		return true
+	}
+	if t, _ := h.Schema.OperationType(r.Context(), p); t == query.Mutation {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "mutations can only be executed using POST requests", http.StatusMethodNotAllowed)
+		return false
+	}
+	return true
+}
+
+// readSSEParams reads the operation from the JSON body of POST requests or from the
+// query string of GET requests.
+func readSSEParams(r *http.Request) (*graphql.Request, error) {
+	if r.Method != http.MethodPost {
+		return paramsFromQuery(r.URL.Query())
+	}
+	var p graphql.Request
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
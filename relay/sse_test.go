@@ -0,0 +1,278 @@
+package relay_test
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+// readEvents reads up to n server-sent events (including keepalive comments) or
+// until the stream ends if n is negative.
+func readEvents(t *testing.T, body *bufio.Reader, n int) []string {
+	t.Helper()
+	var events []string
+	var cur strings.Builder
+	for {
+		line, err := body.ReadString('\n')
+		if err != nil {
+			break
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			events = append(events, cur.String())
+			cur.Reset()
+			if len(events) == n {
+				break
+			}
+			continue
+		}
+		if cur.Len() > 0 {
+			cur.WriteByte('\n')
+		}
+		cur.WriteString(line)
+	}
+	return events
+}
+
+func expectEvents(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %d: %q", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d:\n got: %q\nwant: %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSSEHandlerDistinctConnections(t *testing.T) {
+	srv := httptest.NewServer(&relay.SSEHandler{Schema: countSchema})
+	defer srv.Close()
+
+	t.Run("subscription", func(t *testing.T) {
+		resp, err := http.Post(srv.URL, "application/json", strings.NewReader(`{"query":"subscription { count(to: 2) }"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream; charset=utf-8" {
+			t.Fatalf("unexpected content type %q", ct)
+		}
+		expectEvents(t, readEvents(t, bufio.NewReader(resp.Body), -1),
+			"event: next\ndata: {\"data\":{\"count\":1}}",
+			"event: next\ndata: {\"data\":{\"count\":2}}",
+			"event: complete\ndata: ",
+		)
+	})
+
+	t.Run("query over GET", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "?query=" + url.QueryEscape("{ greeting }"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		expectEvents(t, readEvents(t, bufio.NewReader(resp.Body), -1),
+			"event: next\ndata: {\"data\":{\"greeting\":\"hello\"}}",
+			"event: complete\ndata: ",
+		)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		resp, err := http.Post(srv.URL, "application/json", strings.NewReader(`{"query":"subscription { unknown }"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		expectEvents(t, readEvents(t, bufio.NewReader(resp.Body), -1),
+			"event: next\ndata: {\"errors\":[{\"message\":\"Cannot query field \\\"unknown\\\" on type \\\"Subscription\\\".\",\"locations\":[{\"line\":1,\"column\":16}]}]}",
+			"event: complete\ndata: ",
+		)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		resp, err := http.Post(srv.URL, "application/json", strings.NewReader(`{`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected status 400, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestSSEHandlerGETMutation(t *testing.T) {
+	srv := httptest.NewServer(&relay.SSEHandler{Schema: starwarsSchema})
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?query=" + url.QueryEscape(`mutation { createReview(episode: JEDI, review: {stars: 5}) { stars } }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status 405, got %d", resp.StatusCode)
+	}
+	if allow := resp.Header.Get("Allow"); allow != http.MethodPost {
+		t.Errorf("unexpected Allow header %q", allow)
+	}
+}
+
+func TestSSEHandlerKeepAlive(t *testing.T) {
+	srv := httptest.NewServer(&relay.SSEHandler{Schema: countSchema, KeepAlive: 10 * time.Millisecond})
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPut, srv.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	// An idle stream only receives keepalive comments.
+	req, _ = http.NewRequest(http.MethodGet, srv.URL+"?token="+string(token), nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	expectEvents(t, readEvents(t, bufio.NewReader(resp.Body), 2), ":", ":")
+}
+
+func TestSSEHandlerSingleConnection(t *testing.T) {
+	srv := httptest.NewServer(&relay.SSEHandler{Schema: countSchema})
+	defer srv.Close()
+
+	do := func(method, body string, token string, query string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(method, srv.URL+query, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set(relay.SSETokenHeader, token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := do(http.MethodPut, "", "", "")
+	tokenBytes, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", resp.StatusCode)
+	}
+	token := string(tokenBytes)
+
+	stream := do(http.MethodGet, "", token, "")
+	defer stream.Body.Close()
+	if stream.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", stream.StatusCode)
+	}
+	events := bufio.NewReader(stream.Body)
+
+	conflict := do(http.MethodGet, "", token, "")
+	conflict.Body.Close()
+	if conflict.StatusCode != http.StatusConflict {
+		t.Fatalf("expected status 409 for a second stream, got %d", conflict.StatusCode)
+	}
+
+	resp = do(http.MethodPost, `{"query":"subscription { count(to: 2) }","extensions":{"operationId":"a"}}`, token, "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d", resp.StatusCode)
+	}
+	expectEvents(t, readEvents(t, events, 3),
+		"event: next\ndata: {\"id\":\"a\",\"payload\":{\"data\":{\"count\":1}}}",
+		"event: next\ndata: {\"id\":\"a\",\"payload\":{\"data\":{\"count\":2}}}",
+		"event: complete\ndata: {\"id\":\"a\"}",
+	)
+
+	resp = do(http.MethodPost, `{"query":"{ greeting }"}`, token, "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400 without operation id, got %d", resp.StatusCode)
+	}
+
+	resp = do(http.MethodPost, `{"query":"subscription { count(to: 1000000) }","extensions":{"operationId":"b"}}`, token, "")
+	resp.Body.Close()
+	readEvents(t, events, 1)
+	resp = do(http.MethodDelete, "", token, "?operationId=b")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	resp = do(http.MethodPost, `{"query":"{ greeting }","extensions":{"operationId":"c"}}`, token, "")
+	resp.Body.Close()
+	for range 10000 {
+		e := readEvents(t, events, 1)[0]
+		if strings.Contains(e, `"id":"b"`) {
+			if strings.HasPrefix(e, "event: complete") {
+				t.Fatal("operation stopped by the client must not complete")
+			}
+			continue
+		}
+		if e == "event: complete\ndata: {\"id\":\"c\"}" {
+			return
+		}
+		if e != "event: next\ndata: {\"id\":\"c\",\"payload\":{\"data\":{\"greeting\":\"hello\"}}}" {
+			t.Fatalf("unexpected event %q", e)
+		}
+	}
+	t.Fatal("operation c did not complete")
+}
+
+func TestSSEHandlerUnknownToken(t *testing.T) {
+	h := &relay.SSEHandler{Schema: countSchema}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/?token=unknown", nil)
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestSSEHandlerReservations(t *testing.T) {
+	h := &relay.SSEHandler{Schema: countSchema, ReservationTimeout: 20 * time.Millisecond, MaxReservations: 2}
+	reserve := func() (int, string) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", nil))
+		return w.Code, w.Body.String()
+	}
+
+	_, token := reserve()
+	reserve()
+	if code, _ := reserve(); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503 beyond the maximum, got %d", code)
+	}
+
+	// Operations are rejected until the stream is open.
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"query":"subscription { count(to: 1) }","extensions":{"operationId":"a"}}`))
+	r.Header.Set(relay.SSETokenHeader, token)
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 before the stream is open, got %d", w.Code)
+	}
+
+	// Reservations which are not opened in time are released.
+	time.Sleep(50 * time.Millisecond)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?token="+token, nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 for an expired reservation, got %d", w.Code)
+	}
+	if code, _ := reserve(); code != http.StatusCreated {
+		t.Fatalf("expected status 201 after the reservations expired, got %d", code)
+	}
+}
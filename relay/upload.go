@@ -0,0 +1,273 @@
+package relay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const (
+	mediaTypeMultipart   = "multipart/form-data"
+	defaultMaxUploadSize = 32 << 20 // 32MB
+	multipartMemory      = 8 << 20  // 8MB, larger files are stored in temporary files
+)
+
+// errUploadTooLarge is returned when a multipart request exceeds [Handler.MaxUploadSize].
+var errUploadTooLarge = errors.New("request body too large")
+
+// readMultipart reads the operations of a GraphQL multipart request and binds the uploaded files
+// to the variables referenced by its map. The operations field may hold a batch of operations, in
+// which case the object paths are prefixed with the index of the operation. The parts are read
+// one by one, so that a request with more than MaxUploadFiles files is rejected without reading
+// the remaining files. The returned cleanup function removes temporary files and must be called
+// once the operations have been executed.
+//
+// See https://github.com/jaydenseric/graphql-multipart-request-spec
+func (h *Handler) readMultipart(w http.ResponseWriter, r *http.Request) (ps []*graphql.Request, batch bool, cleanup func(), err error) {
+	maxSize := h.MaxUploadSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxUploadSize
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+	form, err := readMultipartForm(r, h.MaxUploadFiles)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) || err == errUploadTooLarge {
+			return nil, false, nil, errUploadTooLarge
+		}
+		return nil, false, nil, fmt.Errorf("invalid multipart request: %w", err)
+	}
+	var files []io.Closer
+	removeAll := func() {
+		for _, f := range files {
+			f.Close()
+		}
+		form.removeAll()
+	}
+	defer func() {
+		if err != nil {
+			removeAll()
+		}
+	}()
+
+	operations := form.values["operations"]
+	if len(operations) != 1 {
+		return nil, false, nil, errors.New(`invalid multipart request: missing "operations" field`)
+	}
+	if ps, batch, err = decodeParams([]byte(operations[0])); err != nil {
+		return nil, false, nil, fmt.Errorf(`invalid multipart request: invalid "operations" field: %w`, err)
+	}
+
+	for key, paths := range form.fileMap {
+		uploaded := form.files[key]
+		if len(uploaded) != 1 {
+			return nil, false, nil, fmt.Errorf("invalid multipart request: missing file %q", key)
+		}
+		uf := uploaded[0]
+		for _, path := range paths {
+			f, err := uf.open()
+			if err != nil {
+				return nil, false, nil, fmt.Errorf("invalid multipart request: file %q: %w", key, err)
+			}
+			files = append(files, f)
+			upload := graphql.Upload{
+				File:        f,
+				Filename:    uf.filename,
+				ContentType: uf.contentType,
+				Size:        uf.size,
+			}
+			if err := bindUpload(ps, batch, strings.Split(path, "."), upload); err != nil {
+				return nil, false, nil, fmt.Errorf("invalid multipart request: file %q: %w", key, err)
+			}
+		}
+	}
+	return ps, batch, removeAll, nil
+}
+
+// multipartForm holds the fields and the files of a multipart request.
+type multipartForm struct {
+	values  map[string][]string
+	fileMap map[string][]string // the object paths of the files by field name
+	files   map[string][]*multipartFile
+}
+
+// multipartFile is a file of a multipart request, which is held in memory or, if it is too large,
+// in a temporary file.
+type multipartFile struct {
+	filename    string
+	contentType string
+	size        int64
+	content     []byte
+	tmpFile     string
+}
+
+func (f *multipartFile) open() (io.ReadCloser, error) {
+	if f.tmpFile != "" {
+		return os.Open(f.tmpFile)
+	}
+	return io.NopCloser(bytes.NewReader(f.content)), nil
+}
+
+// removeAll removes the temporary files of the form.
+func (form *multipartForm) removeAll() {
+	for _, files := range form.files {
+		for _, f := range files {
+			if f.tmpFile != "" {
+				os.Remove(f.tmpFile)
+			}
+		}
+	}
+}
+
+// readMultipartForm reads the parts of a multipart request. Field values and files share
+// multipartMemory bytes of memory, larger files are stored in temporary files. If maxFiles is
+// positive, it fails as soon as the map or the parts of the request have more than maxFiles files.
+func readMultipartForm(r *http.Request, maxFiles int) (_ *multipartForm, err error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	form := &multipartForm{values: make(map[string][]string), files: make(map[string][]*multipartFile)}
+	defer func() {
+		if err != nil {
+			form.removeAll()
+		}
+	}()
+
+	memory := int64(multipartMemory)
+	numFiles := 0
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return form, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := part.FormName()
+		if name == "" {
+			continue
+		}
+
+		if part.FileName() == "" {
+			var b bytes.Buffer
+			n, err := io.CopyN(&b, part, memory+1)
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			if memory -= n; memory < 0 {
+				return nil, errUploadTooLarge
+			}
+			form.values[name] = append(form.values[name], b.String())
+			if name == "map" {
+				if err := json.Unmarshal(b.Bytes(), &form.fileMap); err != nil {
+					return nil, fmt.Errorf(`invalid "map" field: %w`, err)
+				}
+				if maxFiles > 0 && len(form.fileMap) > maxFiles {
+					return nil, fmt.Errorf("%d files exceed the maximum of %d files", len(form.fileMap), maxFiles)
+				}
+			}
+			continue
+		}
+
+		if numFiles++; maxFiles > 0 && numFiles > maxFiles {
+			return nil, fmt.Errorf("more than %d files", maxFiles)
+		}
+		f, err := readMultipartFile(part, &memory)
+		if err != nil {
+			return nil, err
+		}
+		form.files[name] = append(form.files[name], f)
+	}
+}
+
+// readMultipartFile reads a file part into memory if it fits into the remaining memory, or into a
+// temporary file otherwise.
+func readMultipartFile(part *multipart.Part, memory *int64) (*multipartFile, error) {
+	f := &multipartFile{filename: part.FileName(), contentType: part.Header.Get("Content-Type")}
+	var b bytes.Buffer
+	n, err := io.CopyN(&b, part, *memory+1)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if n <= *memory {
+		*memory -= n
+		f.content, f.size = b.Bytes(), n
+		return f, nil
+	}
+
+	tmp, err := os.CreateTemp("", "graphql-upload-")
+	if err != nil {
+		return nil, err
+	}
+	defer tmp.Close()
+	f.tmpFile = tmp.Name()
+	size, err := io.Copy(tmp, io.MultiReader(&b, part))
+	if err != nil {
+		os.Remove(f.tmpFile)
+		return nil, err
+	}
+	f.size = size
+	return f, nil
+}
+
+// bindUpload replaces the null value at the object path (e.g. "variables.input.files.0", or
+// "1.variables.file" in a batch) with the upload.
+func bindUpload(ps []*graphql.Request, batch bool, path []string, upload graphql.Upload) error {
+	objectPath := strings.Join(path, ".")
+	p := ps[0]
+	if batch {
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 || i >= len(ps) {
+			return fmt.Errorf("invalid object path %q", objectPath)
+		}
+		p, path = ps[i], path[1:]
+	}
+	if len(path) < 2 || path[0] != "variables" || p.Variables == nil {
+		return fmt.Errorf("invalid object path %q", objectPath)
+	}
+	return setPath(p.Variables, path[1:], upload)
+}
+
+func setPath(container any, path []string, v any) error {
+	key := path[0]
+	switch c := container.(type) {
+	case map[string]any:
+		cur, ok := c[key]
+		if !ok {
+			return fmt.Errorf("variable path %q not found", key)
+		}
+		if len(path) == 1 {
+			if cur != nil {
+				return fmt.Errorf("variable path %q must be null", key)
+			}
+			c[key] = v
+			return nil
+		}
+		return setPath(cur, path[1:], v)
+	case []any:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(c) {
+			return fmt.Errorf("invalid list index %q", key)
+		}
+		if len(path) == 1 {
+			if c[i] != nil {
+				return fmt.Errorf("list index %q must be null", key)
+			}
+			c[i] = v
+			return nil
+		}
+		return setPath(c[i], path[1:], v)
+	default:
+		return fmt.Errorf("variable path %q not found", key)
+	}
+}
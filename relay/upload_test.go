@@ -0,0 +1,254 @@
+package relay_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+const uploadSchema = `
+	scalar Upload
+
+	schema {
+		query: Query
+		mutation: Mutation
+	}
+
+	type Query {
+		ok: Boolean!
+	}
+
+	type Mutation {
+		upload(file: Upload!): String!
+		uploadMany(files: [Upload!]!): [String!]!
+	}
+`
+
+type uploadResolver struct{}
+
+func (*uploadResolver) Ok() bool { return true }
+
+func (*uploadResolver) Upload(args struct{ File graphql.Upload }) (string, error) {
+	return describeUpload(args.File)
+}
+
+func (*uploadResolver) UploadMany(args struct{ Files []graphql.Upload }) ([]string, error) {
+	var out []string
+	for _, f := range args.Files {
+		s, err := describeUpload(f)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func describeUpload(u graphql.Upload) (string, error) {
+	content, err := io.ReadAll(u.File)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s (%s, %d bytes): %s", u.Filename, u.ContentType, u.Size, content), nil
+}
+
+type uploadFile struct {
+	field, name, content string
+}
+
+func multipartRequest(t *testing.T, operations, fileMap string, files ...uploadFile) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("operations", operations)
+	if fileMap != "" {
+		_ = mw.WriteField("map", fileMap)
+	}
+	for _, f := range files {
+		part, err := mw.CreatePart(map[string][]string{
+			"Content-Disposition": {fmt.Sprintf(`form-data; name=%q; filename=%q`, f.field, f.name)},
+			"Content-Type":        {"text/plain"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(part, f.content)
+	}
+	mw.Close()
+	r := httptest.NewRequest(http.MethodPost, "/graphql", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func TestServeHTTPUpload(t *testing.T) {
+	schema := graphql.MustParseSchema(uploadSchema, &uploadResolver{})
+
+	for _, tc := range []struct {
+		name       string
+		handler    *relay.Handler
+		request    func(t *testing.T) *http.Request
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:    "single file",
+			handler: &relay.Handler{Schema: schema},
+			request: func(t *testing.T) *http.Request {
+				return multipartRequest(t,
+					`{"query":"mutation($file: Upload!) { upload(file: $file) }","variables":{"file":null}}`,
+					`{"0":["variables.file"]}`,
+					uploadFile{"0", "a.txt", "Alpha"},
+				)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"data":{"upload":"a.txt (text/plain, 5 bytes): Alpha"}}`,
+		},
+		{
+			name:    "file list",
+			handler: &relay.Handler{Schema: schema, MaxUploadFiles: 2},
+			request: func(t *testing.T) *http.Request {
+				return multipartRequest(t,
+					`{"query":"mutation($files: [Upload!]!) { uploadMany(files: $files) }","variables":{"files":[null,null]}}`,
+					`{"0":["variables.files.0"],"1":["variables.files.1"]}`,
+					uploadFile{"0", "a.txt", "Alpha"},
+					uploadFile{"1", "b.txt", "Beta"},
+				)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"data":{"uploadMany":["a.txt (text/plain, 5 bytes): Alpha","b.txt (text/plain, 4 bytes): Beta"]}}`,
+		},
+		{
+			name:    "too many files",
+			handler: &relay.Handler{Schema: schema, MaxUploadFiles: 1},
+			request: func(t *testing.T) *http.Request {
+				return multipartRequest(t,
+					`{"query":"mutation($files: [Upload!]!) { uploadMany(files: $files) }","variables":{"files":[null,null]}}`,
+					`{"0":["variables.files.0"],"1":["variables.files.1"]}`,
+					uploadFile{"0", "a.txt", "Alpha"},
+					uploadFile{"1", "b.txt", "Beta"},
+				)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"errors":[{"message":"invalid multipart request: 2 files exceed the maximum of 1 files"}]}`,
+		},
+		{
+			name:    "too large",
+			handler: &relay.Handler{Schema: schema, MaxUploadSize: 100},
+			request: func(t *testing.T) *http.Request {
+				return multipartRequest(t,
+					`{"query":"mutation($file: Upload!) { upload(file: $file) }","variables":{"file":null}}`,
+					`{"0":["variables.file"]}`,
+					uploadFile{"0", "a.txt", strings.Repeat("x", 1000)},
+				)
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   `{"errors":[{"message":"request body too large"}]}`,
+		},
+		{
+			name:    "missing file",
+			handler: &relay.Handler{Schema: schema},
+			request: func(t *testing.T) *http.Request {
+				return multipartRequest(t,
+					`{"query":"mutation($file: Upload!) { upload(file: $file) }","variables":{"file":null}}`,
+					`{"0":["variables.file"]}`,
+				)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"errors":[{"message":"invalid multipart request: missing file \"0\""}]}`,
+		},
+		{
+			name:    "invalid path",
+			handler: &relay.Handler{Schema: schema},
+			request: func(t *testing.T) *http.Request {
+				return multipartRequest(t,
+					`{"query":"mutation($file: Upload!) { upload(file: $file) }","variables":{"file":null}}`,
+					`{"0":["variables.other"]}`,
+					uploadFile{"0", "a.txt", "Alpha"},
+				)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"errors":[{"message":"invalid multipart request: file \"0\": variable path \"other\" not found"}]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tc.handler.ServeHTTP(w, tc.request(t))
+			if w.Code != tc.wantStatus {
+				t.Errorf("expected status %d, got %d", tc.wantStatus, w.Code)
+			}
+			if got := w.Body.String(); got != tc.wantBody {
+				t.Errorf("unexpected body:\n got: %s\nwant: %s", got, tc.wantBody)
+			}
+		})
+	}
+}
+
+// countingReader counts the bytes read from it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func TestServeHTTPUploadStopsAfterMaxFiles(t *testing.T) {
+	schema := graphql.MustParseSchema(uploadSchema, &uploadResolver{})
+	h := &relay.Handler{Schema: schema, MaxUploadFiles: 1}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("operations", `{"query":"mutation($file: Upload!) { upload(file: $file) }","variables":{"file":null}}`)
+	_ = mw.WriteField("map", `{"0":["variables.file"]}`)
+	for _, field := range []string{"0", "1"} {
+		part, _ := mw.CreateFormFile(field, field+".txt")
+		io.WriteString(part, "content")
+	}
+	// The third file exceeds the limit, so its content must not be read.
+	_, _ = mw.CreateFormFile("2", "2.txt")
+	rest := &countingReader{r: strings.NewReader(strings.Repeat("x", 16<<20))}
+	r := httptest.NewRequest(http.MethodPost, "/graphql", io.MultiReader(&body, rest))
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Code)
+	}
+	if want := `{"errors":[{"message":"invalid multipart request: more than 1 files"}]}`; w.Body.String() != want {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", w.Body, want)
+	}
+	if rest.n > 64<<10 {
+		t.Errorf("read %d bytes of the file exceeding the limit", rest.n)
+	}
+}
+
+func TestServeHTTPUploadLargeFile(t *testing.T) {
+	schema := graphql.MustParseSchema(uploadSchema, &uploadResolver{})
+	h := &relay.Handler{Schema: schema}
+
+	content := strings.Repeat("x", 9<<20) // larger than the memory for uploads
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, multipartRequest(t,
+		`{"query":"mutation($file: Upload!) { upload(file: $file) }","variables":{"file":null}}`,
+		`{"0":["variables.file"]}`,
+		uploadFile{"0", "a.txt", content},
+	))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %.200s", w.Code, w.Body)
+	}
+	if want := fmt.Sprintf(`{"data":{"upload":"a.txt (text/plain, %d bytes): %s"}}`, len(content), content); w.Body.String() != want {
+		t.Errorf("unexpected body of %d bytes", w.Body.Len())
+	}
+}
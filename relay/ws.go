@@ -0,0 +1,358 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/websocket"
+)
+
+// GraphQLTransportWS is the WebSocket subprotocol implemented by [WebSocketHandler].
+//
+// See https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md
+const GraphQLTransportWS = "graphql-transport-ws"
+
+const (
+	defaultConnectionInitTimeout = 3 * time.Second
+	defaultWSWriteTimeout        = 10 * time.Second
+)
+
+// Message types of the graphql-transport-ws protocol.
+const (
+	wsConnectionInit = "connection_init"
+	wsConnectionAck  = "connection_ack"
+	wsPing           = "ping"
+	wsPong           = "pong"
+	wsSubscribe      = "subscribe"
+	wsNext           = "next"
+	wsError          = "error"
+	wsComplete       = "complete"
+)
+
+// Close codes of the graphql-transport-ws protocol.
+const (
+	wsCloseBadRequest              = 4400
+	wsCloseUnauthorized            = 4401
+	wsCloseForbidden               = 4403
+	wsCloseSubprotocolNotAccepted  = 4406
+	wsCloseInitTimeout             = 4408
+	wsCloseSubscriberAlreadyExists = 4409
+	wsCloseTooManyInitRequests     = 4429
+)
+
+// WebSocketHandler serves GraphQL operations over WebSocket connections using the
+// graphql-transport-ws protocol. Queries and mutations are executed with
+// [graphql.Schema.ExecRequest] and subscriptions with [graphql.Schema.SubscribeRequest].
+type WebSocketHandler struct {
+	Schema *graphql.Schema
+
+	// InitFunc is called with the payload of the connection_init message. It can be used to
+	// authenticate the connection and to enrich the context used for all operations of the
+	// connection. Returning an error closes the connection with code 4403 (Forbidden).
+	InitFunc func(ctx context.Context, payload map[string]any) (context.Context, error)
+
+	// ConnectionInitTimeout is the time allowed for the client to send connection_init after
+	// the connection has been established. The default is 3 seconds.
+	ConnectionInitTimeout time.Duration
+
+	// WriteTimeout is the time allowed for writing a message to the client. Clients which do not
+	// read their messages in time are disconnected. The default is 10 seconds.
+	WriteTimeout time.Duration
+
+	// MaxMessageSize limits the size in bytes of a message received from the client. Larger
+	// messages close the connection with code 1009 (Message Too Big). The default is 1MB.
+	MaxMessageSize int64
+
+	// CheckOrigin reports whether the handshake request is allowed. When nil, requests carrying
+	// an Origin header are only accepted if the origin host matches the request host.
+	CheckOrigin func(r *http.Request) bool
+}
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	checkOrigin := h.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(r) {
+		http.Error(w, "websocket: origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	conn, err := websocket.Upgrade(w, r, []string{GraphQLTransportWS})
+	if err != nil {
+		return
+	}
+	conn.WriteTimeout = h.WriteTimeout
+	if conn.WriteTimeout <= 0 {
+		conn.WriteTimeout = defaultWSWriteTimeout
+	}
+	if h.MaxMessageSize > 0 {
+		conn.MaxMessageLen = h.MaxMessageSize
+	}
+	if conn.Subprotocol() != GraphQLTransportWS {
+		conn.Close(wsCloseSubprotocolNotAccepted, "Subprotocol not acceptable")
+		return
+	}
+
+	// The request context is cancelled once ServeHTTP returns, which happens only after the
+	// read loop below terminates, so it can be used as the connection context.
+	ctx, cancel := context.WithCancel(r.Context())
+	c := &wsConnection{
+		handler: h,
+		conn:    conn,
+		ctx:     ctx,
+		subs:    make(map[string]context.CancelFunc),
+	}
+	defer func() {
+		cancel()
+		c.wg.Wait()
+	}()
+
+	timeout := h.ConnectionInitTimeout
+	if timeout <= 0 {
+		timeout = defaultConnectionInitTimeout
+	}
+	initTimer := time.AfterFunc(timeout, func() {
+		c.mu.Lock()
+		initialized := c.initialized
+		c.mu.Unlock()
+		if !initialized {
+			conn.Close(wsCloseInitTimeout, "Connection initialisation timeout")
+		}
+	})
+	defer initTimer.Stop()
+
+	c.readLoop()
+}
+
+type wsConnection struct {
+	handler *WebSocketHandler
+	conn    *websocket.Conn
+	ctx     context.Context
+	wg      sync.WaitGroup
+
+	mu          sync.Mutex
+	initialized bool
+	acked       bool
+	subs        map[string]context.CancelFunc
+}
+
+func (c *wsConnection) readLoop() {
+	for {
+		op, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.conn.Close(websocket.CloseNormal, "")
+			return
+		}
+		if op != websocket.OpText {
+			c.conn.Close(wsCloseBadRequest, "Invalid message received")
+			return
+		}
+
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.conn.Close(wsCloseBadRequest, "Invalid message received")
+			return
+		}
+
+		if !c.handleMessage(&msg) {
+			return
+		}
+	}
+}
+
+// handleMessage processes a single client message. It returns false if the connection was closed.
+func (c *wsConnection) handleMessage(msg *wsMessage) bool {
+	switch msg.Type {
+	case wsConnectionInit:
+		c.mu.Lock()
+		initialized := c.initialized
+		c.initialized = true
+		c.mu.Unlock()
+		if initialized {
+			c.conn.Close(wsCloseTooManyInitRequests, "Too many initialisation requests")
+			return false
+		}
+
+		var payload map[string]any
+		if len(msg.Payload) > 0 {
+			if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+				c.conn.Close(wsCloseBadRequest, "Invalid message received")
+				return false
+			}
+		}
+		if c.handler.InitFunc != nil {
+			ctx, err := c.handler.InitFunc(c.ctx, payload)
+			if err != nil {
+				c.conn.Close(wsCloseForbidden, "Forbidden")
+				return false
+			}
+			if ctx != nil {
+				c.ctx = ctx
+			}
+		}
+
+		c.mu.Lock()
+		c.acked = true
+		c.mu.Unlock()
+		c.send(&wsMessage{Type: wsConnectionAck})
+
+	case wsPing:
+		c.send(&wsMessage{Type: wsPong})
+
+	case wsPong:
+		// nothing to do
+
+	case wsSubscribe:
+		c.mu.Lock()
+		acked := c.acked
+		c.mu.Unlock()
+		if !acked {
+			c.conn.Close(wsCloseUnauthorized, "Unauthorized")
+			return false
+		}
+
+		var p graphql.Request
+		if msg.ID == "" || json.Unmarshal(msg.Payload, &p) != nil {
+			c.conn.Close(wsCloseBadRequest, "Invalid message received")
+			return false
+		}
+
+		c.mu.Lock()
+		if _, exists := c.subs[msg.ID]; exists {
+			c.mu.Unlock()
+			c.conn.Close(wsCloseSubscriberAlreadyExists, fmt.Sprintf("Subscriber for %s already exists", msg.ID))
+			return false
+		}
+		ctx, cancel := context.WithCancel(c.ctx)
+		c.subs[msg.ID] = cancel
+		c.mu.Unlock()
+
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.execute(ctx, msg.ID, &p)
+		}()
+
+	case wsComplete:
+		c.remove(msg.ID)
+
+	default:
+		c.conn.Close(wsCloseBadRequest, "Invalid message received")
+		return false
+	}
+	return true
+}
+
+// execute runs a single operation and streams its results to the client.
+func (c *wsConnection) execute(ctx context.Context, id string, p *graphql.Request) {
+	if !isSubscription(ctx, c.handler.Schema, p) {
+		resp := c.handler.Schema.ExecRequest(ctx, p)
+		if isRequestError(resp) {
+			c.fail(id, resp.Errors)
+			return
+		}
+		c.sendNext(id, resp)
+		c.complete(id)
+		return
+	}
+
+	responses, err := c.handler.Schema.SubscribeRequest(ctx, p)
+	if err != nil {
+		c.fail(id, []*errors.QueryError{errors.Errorf("%s", err)})
+		return
+	}
+	first := true
+	for r := range responses {
+		resp := r.(*graphql.Response)
+		if first && isRequestError(resp) {
+			c.fail(id, resp.Errors)
+			return
+		}
+		first = false
+		c.sendNext(id, resp)
+	}
+	c.complete(id)
+}
+
+// isRequestError reports whether resp describes an operation that failed before execution started.
+func isRequestError(resp *graphql.Response) bool {
+	return resp.Data == nil && len(resp.Errors) > 0
+}
+
+func (c *wsConnection) sendNext(id string, resp *graphql.Response) {
+	payload, err := json.Marshal(resp)
+	if err != nil {
+		c.fail(id, []*errors.QueryError{errors.Errorf("%s", err)})
+		return
+	}
+	c.send(&wsMessage{ID: id, Type: wsNext, Payload: payload})
+}
+
+// fail terminates the operation with an error message.
+func (c *wsConnection) fail(id string, errs []*errors.QueryError) {
+	if c.remove(id) {
+		c.sendErrors(id, errs)
+	}
+}
+
+func (c *wsConnection) sendErrors(id string, errs []*errors.QueryError) {
+	payload, err := json.Marshal(errs)
+	if err != nil {
+		return
+	}
+	c.send(&wsMessage{ID: id, Type: wsError, Payload: payload})
+}
+
+// complete sends a complete message unless the client already completed the operation itself.
+func (c *wsConnection) complete(id string) {
+	if c.remove(id) {
+		c.send(&wsMessage{ID: id, Type: wsComplete})
+	}
+}
+
+// remove cancels the operation with the given id and reports whether it was still running.
+func (c *wsConnection) remove(id string) bool {
+	c.mu.Lock()
+	cancel, ok := c.subs[id]
+	delete(c.subs, id)
+	c.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+func (c *wsConnection) send(msg *wsMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	_ = c.conn.WriteMessage(websocket.OpText, data)
+}
+
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
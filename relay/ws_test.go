@@ -0,0 +1,357 @@
+package relay_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+const subscriptionSchema = `
+	schema {
+		query: Query
+		subscription: Subscription
+	}
+
+	type Query {
+		greeting: String!
+		user: String!
+	}
+
+	type Subscription {
+		count(to: Int!): Int!
+	}
+`
+
+type userKey struct{}
+
+type subscriptionResolver struct{}
+
+func (*subscriptionResolver) Greeting() string { return "hello" }
+
+func (*subscriptionResolver) User(ctx context.Context) string {
+	u, _ := ctx.Value(userKey{}).(string)
+	return u
+}
+
+func (*subscriptionResolver) Count(ctx context.Context, args struct{ To int32 }) <-chan int32 {
+	c := make(chan int32)
+	go func() {
+		defer close(c)
+		for i := int32(1); i <= args.To; i++ {
+			select {
+			case c <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c
+}
+
+var countSchema = graphql.MustParseSchema(subscriptionSchema, &subscriptionResolver{})
+
+// wsClient is a minimal graphql-transport-ws test client.
+type wsClient struct {
+	t    *testing.T
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+func dialWS(t *testing.T, url string, subprotocol string) *wsClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var key [16]byte
+	_, _ = rand.Read(key[:])
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", base64.StdEncoding.EncodeToString(key[:]))
+	if subprotocol != "" {
+		req.Header.Set("Sec-WebSocket-Protocol", subprotocol)
+	}
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected status 101, got %d", resp.StatusCode)
+	}
+	return &wsClient{t: t, conn: conn, br: br}
+}
+
+func (c *wsClient) send(msg string) {
+	c.t.Helper()
+	var mask [4]byte
+	_, _ = rand.Read(mask[:])
+	frame := []byte{0x81}
+	switch n := len(msg); {
+	case n <= 125:
+		frame = append(frame, 0x80|byte(n))
+	default:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	}
+	frame = append(frame, mask[:]...)
+	for i := range len(msg) {
+		frame = append(frame, msg[i]^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// read returns the next text message, or the close code if the server closed the connection.
+func (c *wsClient) read() (string, int) {
+	c.t.Helper()
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.br, hdr[:]); err != nil {
+		c.t.Fatal(err)
+	}
+	n := int(hdr[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		_, _ = io.ReadFull(c.br, ext[:])
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		_, _ = io.ReadFull(c.br, ext[:])
+		n = int(binary.BigEndian.Uint64(ext[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		c.t.Fatal(err)
+	}
+	if hdr[0]&0x0f == 0x8 {
+		return "", int(binary.BigEndian.Uint16(payload))
+	}
+	return string(payload), 0
+}
+
+func (c *wsClient) expect(want string) {
+	c.t.Helper()
+	got, code := c.read()
+	if code != 0 {
+		c.t.Fatalf("expected message %s, got close %d", want, code)
+	}
+	if got != want {
+		c.t.Fatalf("unexpected message:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func (c *wsClient) expectClose(want int) {
+	c.t.Helper()
+	msg, code := c.read()
+	if code != want {
+		c.t.Fatalf("expected close %d, got code %d (message %q)", want, code, msg)
+	}
+}
+
+func TestWebSocketHandler(t *testing.T) {
+	t.Run("subscription", func(t *testing.T) {
+		srv := httptest.NewServer(&relay.WebSocketHandler{Schema: countSchema})
+		defer srv.Close()
+
+		c := dialWS(t, srv.URL, relay.GraphQLTransportWS)
+		c.send(`{"type":"connection_init"}`)
+		c.expect(`{"type":"connection_ack"}`)
+		c.send(`{"id":"1","type":"subscribe","payload":{"query":"subscription { count(to: 2) }"}}`)
+		c.expect(`{"id":"1","type":"next","payload":{"data":{"count":1}}}`)
+		c.expect(`{"id":"1","type":"next","payload":{"data":{"count":2}}}`)
+		c.expect(`{"id":"1","type":"complete"}`)
+	})
+
+	t.Run("message too big", func(t *testing.T) {
+		srv := httptest.NewServer(&relay.WebSocketHandler{Schema: countSchema, MaxMessageSize: 32})
+		defer srv.Close()
+
+		c := dialWS(t, srv.URL, relay.GraphQLTransportWS)
+		c.send(`{"type":"connection_init"}`)
+		c.expect(`{"type":"connection_ack"}`)
+		c.send(`{"id":"q","type":"subscribe","payload":{"query":"{ greeting }"}}`)
+		c.expectClose(1009)
+	})
+
+	t.Run("query", func(t *testing.T) {
+		srv := httptest.NewServer(&relay.WebSocketHandler{Schema: countSchema})
+		defer srv.Close()
+
+		c := dialWS(t, srv.URL, relay.GraphQLTransportWS)
+		c.send(`{"type":"connection_init"}`)
+		c.expect(`{"type":"connection_ack"}`)
+		c.send(`{"id":"q","type":"subscribe","payload":{"query":"{ greeting }"}}`)
+		c.expect(`{"id":"q","type":"next","payload":{"data":{"greeting":"hello"}}}`)
+		c.expect(`{"id":"q","type":"complete"}`)
+	})
+
+	t.Run("persisted subscription", func(t *testing.T) {
+		schema := graphql.MustParseSchema(subscriptionSchema, &subscriptionResolver{}, graphql.AutomaticPersistedQueries(graphql.NewPersistedQueryCache(10)))
+		srv := httptest.NewServer(&relay.WebSocketHandler{Schema: schema})
+		defer srv.Close()
+
+		// sha256("subscription { count(to: 2) }")
+		const ext = `"extensions":{"persistedQuery":{"version":1,"sha256Hash":"63ca7f09782d687e955096c01d1fc440a7c7f1f0ec84775684957435b76bc99b"}}`
+		c := dialWS(t, srv.URL, relay.GraphQLTransportWS)
+		c.send(`{"type":"connection_init"}`)
+		c.expect(`{"type":"connection_ack"}`)
+		c.send(`{"id":"1","type":"subscribe","payload":{"query":"subscription { count(to: 2) }",` + ext + `}}`)
+		c.expect(`{"id":"1","type":"next","payload":{"data":{"count":1}}}`)
+		c.expect(`{"id":"1","type":"next","payload":{"data":{"count":2}}}`)
+		c.expect(`{"id":"1","type":"complete"}`)
+		c.send(`{"id":"2","type":"subscribe","payload":{` + ext + `}}`)
+		c.expect(`{"id":"2","type":"next","payload":{"data":{"count":1}}}`)
+		c.expect(`{"id":"2","type":"next","payload":{"data":{"count":2}}}`)
+		c.expect(`{"id":"2","type":"complete"}`)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		srv := httptest.NewServer(&relay.WebSocketHandler{Schema: countSchema})
+		defer srv.Close()
+
+		c := dialWS(t, srv.URL, relay.GraphQLTransportWS)
+		c.send(`{"type":"connection_init"}`)
+		c.expect(`{"type":"connection_ack"}`)
+		c.send(`{"id":"1","type":"subscribe","payload":{"query":"subscription { unknown }"}}`)
+		c.expect(`{"id":"1","type":"error","payload":[{"message":"Cannot query field \"unknown\" on type \"Subscription\".","locations":[{"line":1,"column":16}]}]}`)
+		c.send(`{"type":"ping"}`)
+		c.expect(`{"type":"pong"}`)
+	})
+
+	t.Run("init hook", func(t *testing.T) {
+		h := &relay.WebSocketHandler{
+			Schema: countSchema,
+			InitFunc: func(ctx context.Context, payload map[string]any) (context.Context, error) {
+				token, _ := payload["token"].(string)
+				if token == "" {
+					return nil, errors.New("missing token")
+				}
+				return context.WithValue(ctx, userKey{}, token), nil
+			},
+		}
+		srv := httptest.NewServer(h)
+		defer srv.Close()
+
+		c := dialWS(t, srv.URL, relay.GraphQLTransportWS)
+		c.send(`{"type":"connection_init","payload":{"token":"alice"}}`)
+		c.expect(`{"type":"connection_ack"}`)
+		c.send(`{"id":"1","type":"subscribe","payload":{"query":"{ user }"}}`)
+		c.expect(`{"id":"1","type":"next","payload":{"data":{"user":"alice"}}}`)
+		c.expect(`{"id":"1","type":"complete"}`)
+
+		c = dialWS(t, srv.URL, relay.GraphQLTransportWS)
+		c.send(`{"type":"connection_init"}`)
+		c.expectClose(4403)
+	})
+
+	t.Run("protocol violations", func(t *testing.T) {
+		srv := httptest.NewServer(&relay.WebSocketHandler{Schema: countSchema, ConnectionInitTimeout: 50 * time.Millisecond})
+		defer srv.Close()
+
+		c := dialWS(t, srv.URL, "")
+		c.expectClose(4406)
+
+		c = dialWS(t, srv.URL, relay.GraphQLTransportWS)
+		c.send(`{"id":"1","type":"subscribe","payload":{"query":"{ greeting }"}}`)
+		c.expectClose(4401)
+
+		c = dialWS(t, srv.URL, relay.GraphQLTransportWS)
+		c.send(`{"type":"connection_init"}`)
+		c.expect(`{"type":"connection_ack"}`)
+		c.send(`{"type":"connection_init"}`)
+		c.expectClose(4429)
+
+		c = dialWS(t, srv.URL, relay.GraphQLTransportWS)
+		c.send(`{"type":"connection_init"}`)
+		c.expect(`{"type":"connection_ack"}`)
+		c.send(`{"id":"1","type":"subscribe","payload":{"query":"subscription { count(to: 1000000) }"}}`)
+		c.send(`{"id":"1","type":"subscribe","payload":{"query":"subscription { count(to: 1) }"}}`)
+		for {
+			msg, code := c.read()
+			if code != 0 {
+				if code != 4409 {
+					t.Fatalf("expected close 4409, got %d", code)
+				}
+				break
+			}
+			if !strings.Contains(msg, `"type":"next"`) {
+				t.Fatalf("unexpected message %s", msg)
+			}
+		}
+
+		c = dialWS(t, srv.URL, relay.GraphQLTransportWS)
+		c.expectClose(4408)
+
+		c = dialWS(t, srv.URL, relay.GraphQLTransportWS)
+		c.send(`not json`)
+		c.expectClose(4400)
+	})
+
+	t.Run("complete from client", func(t *testing.T) {
+		srv := httptest.NewServer(&relay.WebSocketHandler{Schema: countSchema})
+		defer srv.Close()
+
+		c := dialWS(t, srv.URL, relay.GraphQLTransportWS)
+		c.send(`{"type":"connection_init"}`)
+		c.expect(`{"type":"connection_ack"}`)
+		c.send(`{"id":"1","type":"subscribe","payload":{"query":"subscription { count(to: 1000000) }"}}`)
+		c.expect(`{"id":"1","type":"next","payload":{"data":{"count":1}}}`)
+		c.send(`{"id":"1","type":"complete"}`)
+		c.send(`{"type":"ping"}`)
+		for {
+			msg, code := c.read()
+			if code != 0 {
+				t.Fatalf("unexpected close %d", code)
+			}
+			if msg == `{"type":"pong"}` {
+				break
+			}
+			if !strings.Contains(msg, `"type":"next"`) {
+				t.Fatalf("unexpected message %s", msg)
+			}
+		}
+	})
+}
+
+func TestWebSocketHandlerRejectsCrossOrigin(t *testing.T) {
+	srv := httptest.NewServer(&relay.WebSocketHandler{Schema: countSchema})
+	defer srv.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	req.Header.Set("Origin", "http://evil.example")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d", resp.StatusCode)
+	}
+}
@@ -3,10 +3,77 @@ package graphql
 import (
 	"context"
 	"sort"
+	"strings"
 
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+	"github.com/graph-gophers/graphql-go/internal/exec/selected"
 	"github.com/graph-gophers/graphql-go/internal/exec/selections"
 )
 
+// SelectedField is a field selected in the query, see [SelectionSet].
+type SelectedField struct {
+	// Name is the name of the field in the schema.
+	Name string
+	// Alias is the name of the field in the response, which is the field name if the query does
+	// not use an alias.
+	Alias string
+	// Args holds the coerced arguments of this occurrence of the field. It must not be modified.
+	Args map[string]any
+	// TypeCondition is the name of the object type the field is selected on, if the field is
+	// selected by a fragment on another type than the type of the parent field, e.g. the
+	// fields of "... on Human" selected on a Character. It is empty otherwise.
+	TypeCondition string
+	// Definition is the definition of the field in the schema, including its directives. It must
+	// not be modified.
+	Definition *ast.FieldDefinition
+	// Selections holds the fields selected underneath the field.
+	Selections []SelectedField
+}
+
+// SelectionSet returns the tree of the fields selected underneath the current resolver, e.g. for
+// translating the selection into joins or projections. Unlike [SelectedFieldNames], the tree
+// has a node for every occurrence of a field, carrying its alias, its arguments and the type
+// condition of the enclosing fragment.
+// It returns nil when the current field's return type is a leaf (scalar / enum) or when
+// DisableFieldSelections was used at schema creation.
+//
+// Notes:
+//   - Fragment spreads & inline fragments on the parent type are flattened.
+//   - Fragments on interfaces are expanded into the object types implementing them.
+//   - Meta fields beginning with "__" (including __typename) are excluded.
+func SelectionSet(ctx context.Context) []SelectedField {
+	lazy := selections.FromContext(ctx)
+	if lazy == nil {
+		return nil
+	}
+	return selectedFields(nil, lazy.Selections(), "")
+}
+
+func selectedFields(dst []SelectedField, sels []selected.Selection, typeCondition string) []SelectedField {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *selected.SchemaField:
+			if strings.HasPrefix(sel.Name, "__") {
+				continue
+			}
+			dst = append(dst, SelectedField{
+				Name:          sel.Name,
+				Alias:         sel.Alias,
+				Args:          sel.Args,
+				TypeCondition: typeCondition,
+				Definition:    &sel.FieldDefinition,
+				Selections:    selectedFields(nil, sel.Sels, ""),
+			})
+		case *selected.TypeAssertion:
+			dst = selectedFields(dst, sel.Sels, sel.TypeExec.(*resolvable.Object).Name)
+		case *selected.DeferredFragment:
+			dst = selectedFields(dst, sel.Sels, typeCondition)
+		}
+	}
+	return dst
+}
+
 // SelectedFieldNames returns the set of selected field paths underneath the
 // current resolver. Paths are dot-delimited for nested structures (e.g. "products",
 // "products.id", "products.category.id"). Immediate child field names are always
@@ -0,0 +1,123 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+const selectionTreeSchema = `
+	directive @column(name: String!) on FIELD_DEFINITION
+
+	type Query {
+		search(text: String!): [Result!]!
+		leaf: String!
+	}
+	union Result = Book | Author
+	type Book {
+		title: String! @column(name: "book_title")
+		author: Author!
+		reviews(first: Int): [Review!]!
+	}
+	type Author {
+		name: String!
+	}
+	type Review {
+		stars: Int!
+	}
+`
+
+type selectionTreeResolver struct {
+	tree []graphql.SelectedField
+	leaf []graphql.SelectedField
+}
+
+func (r *selectionTreeResolver) Search(ctx context.Context, args struct{ Text string }) []*selectionTreeResult {
+	r.tree = graphql.SelectionSet(ctx)
+	return nil
+}
+
+func (r *selectionTreeResolver) Leaf(ctx context.Context) string {
+	r.leaf = graphql.SelectionSet(ctx)
+	return ""
+}
+
+type selectionTreeResult struct{}
+
+func (*selectionTreeResult) ToBook() (*selectionTreeBook, bool)     { return nil, false }
+func (*selectionTreeResult) ToAuthor() (*selectionTreeAuthor, bool) { return nil, false }
+
+type selectionTreeBook struct{}
+
+func (*selectionTreeBook) Title() string                                         { return "" }
+func (*selectionTreeBook) Author() *selectionTreeAuthor                          { return nil }
+func (*selectionTreeBook) Reviews(struct{ First *int32 }) []*selectionTreeReview { return nil }
+
+type selectionTreeAuthor struct{}
+
+func (*selectionTreeAuthor) Name() string { return "" }
+
+type selectionTreeReview struct{}
+
+func (*selectionTreeReview) Stars() int32 { return 0 }
+
+// formatSelections formats a selection tree with one line per field.
+func formatSelections(b *strings.Builder, fields []graphql.SelectedField, indent string) {
+	for _, f := range fields {
+		fmt.Fprintf(b, "%s%s:%s on=%q args=%v def=%s", indent, f.Alias, f.Name, f.TypeCondition, f.Args, f.Definition.Type)
+		if d := f.Definition.Directives.Get("column"); d != nil {
+			fmt.Fprintf(b, " @%s", d.Name.Name)
+		}
+		b.WriteString("\n")
+		formatSelections(b, f.Selections, indent+"  ")
+	}
+}
+
+func TestSelectionSet(t *testing.T) {
+	r := &selectionTreeResolver{}
+	s := graphql.MustParseSchema(selectionTreeSchema, r)
+	res := s.Exec(context.Background(), `
+		query {
+			search(text: "go") {
+				__typename
+				... on Book {
+					title
+					headline: title
+					author { name }
+					top: reviews(first: 3) { stars }
+					all: reviews { stars }
+				}
+				...AuthorFields
+			}
+			leaf
+		}
+		fragment AuthorFields on Author {
+			name
+		}
+	`, "", nil)
+	if len(res.Errors) != 0 {
+		t.Fatal(res.Errors)
+	}
+
+	var b strings.Builder
+	formatSelections(&b, r.tree, "")
+	want := `title:title on="Book" args=map[] def=String! @column
+headline:title on="Book" args=map[] def=String! @column
+author:author on="Book" args=map[] def=Author!
+  name:name on="" args=map[] def=String!
+top:reviews on="Book" args=map[first:3] def=[Review!]!
+  stars:stars on="" args=map[] def=Int!
+all:reviews on="Book" args=map[] def=[Review!]!
+  stars:stars on="" args=map[] def=Int!
+name:name on="Author" args=map[] def=String!
+`
+	if got := b.String(); got != want {
+		t.Errorf("got selection tree\n%s\nwant\n%s", got, want)
+	}
+	if r.leaf != nil {
+		t.Errorf("got selections of a leaf field: %v", r.leaf)
+	}
+}
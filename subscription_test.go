@@ -158,7 +158,7 @@ func TestSchemaSubscribe(t *testing.T) {
 					Data: json.RawMessage(`
 						null
 					`),
-					Errors: []*qerrors.QueryError{qerrors.Errorf("%s", errResolver)},
+					Errors: []*qerrors.QueryError{{Message: errResolver.Error(), Locations: []qerrors.Location{{Line: 4, Column: 7}}}},
 				},
 				{
 					Data: json.RawMessage(`
@@ -216,7 +216,7 @@ func TestSchemaSubscribe(t *testing.T) {
 					Data: json.RawMessage(`
 						null
 					`),
-					Errors: []*qerrors.QueryError{qerrors.Errorf("%s", errResolver)},
+					Errors: []*qerrors.QueryError{{Message: errResolver.Error(), Locations: []qerrors.Location{{Line: 3, Column: 6}}}},
 				},
 			},
 		},
@@ -245,7 +245,7 @@ func TestSchemaSubscribe(t *testing.T) {
 							}
 						}
 					`),
-					Errors: []*qerrors.QueryError{qerrors.Errorf("%s", errResolver)},
+					Errors: []*qerrors.QueryError{{Message: errResolver.Error(), Locations: []qerrors.Location{{Line: 4, Column: 7}}}},
 				},
 			},
 		},
@@ -268,7 +268,7 @@ func TestSchemaSubscribe(t *testing.T) {
 							"helloSaidNullable": null
 						}
 					`),
-					Errors: []*qerrors.QueryError{qerrors.Errorf("%s", errResolver)},
+					Errors: []*qerrors.QueryError{{Message: errResolver.Error(), Locations: []qerrors.Location{{Line: 3, Column: 6}}}},
 				},
 			},
 		},
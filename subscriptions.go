@@ -4,13 +4,13 @@ import (
 	"context"
 	"errors"
 
+	"github.com/graph-gophers/graphql-go/ast"
 	qerrors "github.com/graph-gophers/graphql-go/errors"
 	"github.com/graph-gophers/graphql-go/internal/common"
 	"github.com/graph-gophers/graphql-go/internal/exec"
 	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
 	"github.com/graph-gophers/graphql-go/internal/exec/selected"
 	"github.com/graph-gophers/graphql-go/internal/query"
-	"github.com/graph-gophers/graphql-go/internal/validation"
 	"github.com/graph-gophers/graphql-go/introspection"
 )
 
@@ -26,30 +26,56 @@ func (s *Schema) Subscribe(ctx context.Context, queryString string, operationNam
 	if _, ok := s.schema.RootOperationTypes["subscription"]; !ok {
 		return nil, errors.New("no subscriptions are offered by the schema")
 	}
-	return s.subscribe(ctx, queryString, operationName, variables, s.res), nil
+	return s.SubscribeRequest(ctx, &Request{Query: queryString, OperationName: operationName, Variables: variables})
 }
 
-func (s *Schema) subscribe(ctx context.Context, queryString string, operationName string, variables map[string]any, res *resolvable.Schema) <-chan any {
-	if s.maxQueryLength > 0 && len(queryString) > s.maxQueryLength {
-		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{qerrors.Errorf("query length %d exceeds the maximum allowed query length of %d bytes", len(queryString), s.maxQueryLength)}})
+// SubscribeRequest is like [Schema.Subscribe] but takes a [Request], which may use automatic
+// persisted queries or trusted documents.
+func (s *Schema) SubscribeRequest(ctx context.Context, req *Request) (<-chan any, error) {
+	if !s.res.SubscriptionResolver.IsValid() {
+		return nil, errors.New("schema created without resolver, can not subscribe")
 	}
-
-	doc, qErr := query.Parse(queryString)
-	if qErr != nil {
-		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{qErr}})
+	if _, ok := s.schema.RootOperationTypes["subscription"]; !ok {
+		return nil, errors.New("no subscriptions are offered by the schema")
 	}
-
-	validationFinish := s.validationTracer.TraceValidation(ctx)
-	errs := validation.Validate(s.schema, doc, variables, s.maxDepth, s.overlapPairLimit, s.validateDeprecated)
-	validationFinish(errs)
+	_, doc, errs := s.resolveDocument(ctx, req)
 	if len(errs) != 0 {
-		return sendAndReturnClosed(&Response{Errors: errs})
+		return sendAndReturnClosed(&Response{Errors: errs}), nil
 	}
+	return s.subscribeDocument(ctx, doc, req.OperationName, req.Variables, s.res), nil
+}
 
+// subscribeDocument subscribes to an operation of a validated document.
+func (s *Schema) subscribeDocument(ctx context.Context, doc *ast.ExecutableDefinition, operationName string, variables map[string]any, res *resolvable.Schema) <-chan any {
 	op, err := getOperation(doc, operationName)
 	if err != nil {
 		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{qerrors.Errorf("%s", err)}})
 	}
+	return s.subscribeOperation(ctx, doc, op, variables, res)
+}
+
+// subscribeOperation subscribes to an operation of a validated document.
+func (s *Schema) subscribeOperation(ctx context.Context, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]any, res *resolvable.Schema) <-chan any {
+	executesOnce := op.Type == query.Query || op.Type == query.Mutation
+	if executesOnce {
+		// Queries and mutations are executed before returning, like by execOperation.
+		var cancel context.CancelFunc
+		ctx, cancel = s.withExecutionTimeout(ctx)
+		defer cancel()
+	}
+	ctx = withOperation(ctx, op)
+	ctx = withLogger(ctx, s.logger)
+	ctx, extensions, costErrs := s.checkCost(ctx, doc, op, variables)
+	if costErrs != nil {
+		return sendAndReturnClosed(&Response{Errors: costErrs, Extensions: extensions})
+	}
+	if variables == nil {
+		variables = make(map[string]any)
+	}
+	ctx, errs := s.beforeExecution(ctx, doc, op, variables)
+	if errs != nil {
+		return sendAndReturnClosed(&Response{Errors: errs, Extensions: extensions})
+	}
 
 	r := &exec.Request{
 		Request: selected.Request{
@@ -64,6 +90,11 @@ func (s *Schema) subscribe(ctx context.Context, queryString string, operationNam
 		SubscribeResolverTimeout: s.subscribeResolverTimeout,
 		DisableMemoryPooling:     s.disableMemoryPooling,
 		MaxPooledBufferCapacity:  s.maxPooledBufferCapacity,
+		FieldMiddleware:          s.execFieldMiddleware(),
+		SkipTrivialMiddleware:    s.skipTrivialMiddleware,
+		DirectiveHandler:         s.execDirectiveHandler(),
+		FieldTimeouts:            s.execFieldTimeouts(),
+		ErrorPresenter:           s.errorPresenter,
 	}
 	varTypes := make(map[string]*introspection.Type)
 	for _, v := range op.Vars {
@@ -74,9 +105,13 @@ func (s *Schema) subscribe(ctx context.Context, queryString string, operationNam
 		varTypes[v.Name.Name] = introspection.WrapType(t)
 	}
 
-	if op.Type == query.Query || op.Type == query.Mutation {
+	if executesOnce {
+		r.PartialData = s.partialData
+		ctx, collector := withExtensionCollector(ctx)
 		data, errs := r.Execute(ctx, res, op)
-		return sendAndReturnClosed(&Response{Data: data, Errors: errs})
+		resp := &Response{Data: data, Errors: errs, Extensions: collector.merge(extensions)}
+		s.afterExecution(ctx, doc, op, variables, resp)
+		return sendAndReturnClosed(resp)
 	}
 
 	responses := r.Subscribe(ctx, res, op)
@@ -84,8 +119,10 @@ func (s *Schema) subscribe(ctx context.Context, queryString string, operationNam
 	go func() {
 	Loop:
 		for resp := range responses {
+			event := &Response{Data: resp.Data, Errors: resp.Errors}
+			s.onSubscriptionEvent(ctx, doc, op, variables, event)
 			select {
-			case c <- &Response{Data: resp.Data, Errors: resp.Errors}:
+			case c <- event:
 				continue
 
 			case <-ctx.Done():
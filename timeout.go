@@ -0,0 +1,100 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/internal/exec"
+)
+
+// ExecutionTimeout limits the duration of the execution of queries and mutations. When the timeout
+// expires, the context of the execution is cancelled, so that no further resolvers are called. The
+// default is 0 which disables the timeout. It also applies to queries and mutations executed with
+// [Schema.Subscribe]. Subscriptions are not affected.
+func ExecutionTimeout(d time.Duration) SchemaOpt {
+	return func(s *Schema) {
+		s.executionTimeout = d
+	}
+}
+
+// PartialDataOnCancel returns the data resolved before the context of an execution was cancelled,
+// e.g. by its deadline or by [ExecutionTimeout], instead of discarding it. Fields which were not
+// resolved yet resolve to null with the error of the context, e.g. "context deadline exceeded",
+// carrying the path of the field. Null values propagate to nullable parents as usual. By default
+// only the error of the context is returned.
+func PartialDataOnCancel() SchemaOpt {
+	return func(s *Schema) {
+		s.partialData = true
+	}
+}
+
+// FieldTimeouts limits the duration of the resolvers of fields, keyed by "Type.field" where Type
+// is an object type. A field whose resolver does not return in time resolves to null with an error
+// carrying the path of the field, while its sibling fields finish. The context of the resolver is
+// cancelled then.
+//
+// Timeouts can also be declared in the schema with the @timeout directive, which must be defined
+// by the schema with exactly this argument, otherwise @timeout is an ordinary directive which may
+// have a handler (see [DirectiveHandlers]):
+//
+//	directive @timeout(ms: Int!) on FIELD_DEFINITION
+//
+//	type Query {
+//		search(text: String!): [Result!]! @timeout(ms: 500)
+//	}
+//
+// Timeouts passed to FieldTimeouts take precedence over the directive. A timeout of 0 disables the
+// timeout of a field. Multiple calls merge the maps.
+func FieldTimeouts(timeouts map[string]time.Duration) SchemaOpt {
+	return func(s *Schema) {
+		if s.fieldTimeouts == nil {
+			s.fieldTimeouts = make(map[string]time.Duration, len(timeouts))
+		} else {
+			s.fieldTimeouts = maps.Clone(s.fieldTimeouts)
+		}
+		maps.Copy(s.fieldTimeouts, timeouts)
+	}
+}
+
+// withExecutionTimeout returns a context which is cancelled when the execution timeout expires.
+func (s *Schema) withExecutionTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.executionTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, s.executionTimeout)
+}
+
+// execFieldTimeouts returns the field timeouts of the schema in the form used by the executor.
+func (s *Schema) execFieldTimeouts() map[exec.FieldKey]time.Duration {
+	if len(s.fieldTimeouts) == 0 {
+		return nil
+	}
+	timeouts := make(map[exec.FieldKey]time.Duration, len(s.fieldTimeouts))
+	for key, d := range s.fieldTimeouts {
+		typeName, fieldName, _ := strings.Cut(key, ".")
+		timeouts[exec.FieldKey{TypeName: typeName, FieldName: fieldName}] = d
+	}
+	return timeouts
+}
+
+// validateFieldTimeouts checks that the field timeouts refer to fields of the schema.
+func (s *Schema) validateFieldTimeouts() error {
+	for key := range s.fieldTimeouts {
+		typeName, fieldName, ok := strings.Cut(key, ".")
+		if !ok {
+			return fmt.Errorf("invalid field timeout key %q, expected \"Type.field\"", key)
+		}
+		t, ok := s.schema.Types[typeName].(*ast.ObjectTypeDefinition)
+		if !ok {
+			return fmt.Errorf("field timeout %q refers to an unknown object type %q", key, typeName)
+		}
+		if t.Fields.Get(fieldName) == nil {
+			return fmt.Errorf("field timeout %q refers to an unknown field %q of type %q", key, fieldName, typeName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/log"
+)
+
+const timeoutSchema = `
+	directive @timeout(ms: Int!) on FIELD_DEFINITION
+
+	type Query {
+		fast: String!
+		slow: String
+		slowNonNull: String!
+		slowDirective: String @timeout(ms: 20)
+		nested: Nested
+		delayed: Nested
+	}
+	type Nested {
+		value: String!
+		slow: String!
+	}
+`
+
+type timeoutResolver struct{}
+
+func (timeoutResolver) Fast() string {
+	return "fast"
+}
+
+func (timeoutResolver) Slow(ctx context.Context) (*string, error) {
+	return waitForCancel(ctx)
+}
+
+func (timeoutResolver) SlowNonNull(ctx context.Context) (string, error) {
+	_, err := waitForCancel(ctx)
+	return "", err
+}
+
+func (timeoutResolver) SlowDirective(ctx context.Context) (*string, error) {
+	return waitForCancel(ctx)
+}
+
+func (timeoutResolver) Nested() *timeoutNested {
+	return &timeoutNested{}
+}
+
+// Delayed returns after the timeouts of the tests, regardless of the context.
+func (timeoutResolver) Delayed() *timeoutNested {
+	time.Sleep(100 * time.Millisecond)
+	return &timeoutNested{}
+}
+
+type timeoutNested struct{}
+
+func (timeoutNested) Value() string {
+	return "value"
+}
+
+func (timeoutNested) Slow(ctx context.Context) (string, error) {
+	_, err := waitForCancel(ctx)
+	return "", err
+}
+
+// waitForCancel blocks until the context is cancelled or a second has passed.
+func waitForCancel(ctx context.Context) (*string, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(time.Second):
+		s := "slow"
+		return &s, nil
+	}
+}
+
+func TestFieldTimeouts(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		opts  []graphql.SchemaOpt
+		query string
+		want  string
+	}{
+		{
+			name:  "nullable field",
+			opts:  []graphql.SchemaOpt{graphql.FieldTimeouts(map[string]time.Duration{"Query.slow": 20 * time.Millisecond})},
+			query: `{ fast slow }`,
+			want:  `{"errors":[{"message":"graphql: field Query.slow timed out after 20ms","locations":[{"line":1,"column":8}],"path":["slow"]}],"data":{"fast":"fast","slow":null}}`,
+		},
+		{
+			name:  "null propagation",
+			opts:  []graphql.SchemaOpt{graphql.FieldTimeouts(map[string]time.Duration{"Nested.slow": 20 * time.Millisecond})},
+			query: `{ fast nested { value slow } }`,
+			want:  `{"errors":[{"message":"graphql: field Nested.slow timed out after 20ms","locations":[{"line":1,"column":23}],"path":["nested","slow"]}],"data":{"fast":"fast","nested":null}}`,
+		},
+		{
+			name:  "non-null root field",
+			opts:  []graphql.SchemaOpt{graphql.FieldTimeouts(map[string]time.Duration{"Query.slowNonNull": 20 * time.Millisecond})},
+			query: `{ fast slowNonNull }`,
+			want:  `{"errors":[{"message":"graphql: field Query.slowNonNull timed out after 20ms","locations":[{"line":1,"column":8}],"path":["slowNonNull"]}],"data":null}`,
+		},
+		{
+			name:  "directive",
+			query: `{ fast slowDirective }`,
+			want:  `{"errors":[{"message":"graphql: field Query.slowDirective timed out after 20ms","locations":[{"line":1,"column":8}],"path":["slowDirective"]}],"data":{"fast":"fast","slowDirective":null}}`,
+		},
+		{
+			name:  "map overrides directive",
+			opts:  []graphql.SchemaOpt{graphql.FieldTimeouts(map[string]time.Duration{"Query.slowDirective": 30 * time.Millisecond})},
+			query: `{ slowDirective }`,
+			want:  `{"errors":[{"message":"graphql: field Query.slowDirective timed out after 30ms","locations":[{"line":1,"column":3}],"path":["slowDirective"]}],"data":{"slowDirective":null}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := graphql.MustParseSchema(timeoutSchema, &timeoutResolver{}, tc.opts...)
+			start := time.Now()
+			res := s.Exec(context.Background(), tc.query, "", nil)
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("execution took %s", elapsed)
+			}
+			got, err := json.Marshal(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("got %s\nwant %s", got, tc.want)
+			}
+			for _, qErr := range res.Errors {
+				if !errors.Is(qErr.ResolverError, context.DeadlineExceeded) {
+					t.Errorf("resolver error %v is not a deadline exceeded error", qErr.ResolverError)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldTimeoutsValidation(t *testing.T) {
+	for _, tc := range []struct {
+		key  string
+		want string
+	}{
+		{key: "slow", want: `invalid field timeout key "slow", expected "Type.field"`},
+		{key: "Missing.slow", want: `field timeout "Missing.slow" refers to an unknown object type "Missing"`},
+		{key: "Query.missing", want: `field timeout "Query.missing" refers to an unknown field "missing" of type "Query"`},
+	} {
+		t.Run(tc.key, func(t *testing.T) {
+			_, err := graphql.ParseSchema(timeoutSchema, &timeoutResolver{}, graphql.FieldTimeouts(map[string]time.Duration{tc.key: time.Second}))
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("got error %v, want %s", err, tc.want)
+			}
+
+			s := graphql.MustParseSchema(timeoutSchema, &timeoutResolver{})
+			_, err = s.Clone(&timeoutResolver{}, graphql.FieldTimeouts(map[string]time.Duration{tc.key: time.Second}))
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("clone: got error %v, want %s", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecutionTimeout(t *testing.T) {
+	s := graphql.MustParseSchema(timeoutSchema, &timeoutResolver{}, graphql.ExecutionTimeout(20*time.Millisecond))
+	start := time.Now()
+	res := s.Exec(context.Background(), `{ fast slow }`, "", nil)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("execution took %s", elapsed)
+	}
+	if len(res.Errors) == 0 || !strings.Contains(res.Errors[0].Message, context.DeadlineExceeded.Error()) {
+		t.Errorf("got errors %v, want a deadline exceeded error", res.Errors)
+	}
+
+	// The timeout applies to each execution.
+	res = s.Exec(context.Background(), `{ fast }`, "", nil)
+	if len(res.Errors) != 0 || string(res.Data) != `{"fast":"fast"}` {
+		t.Errorf("got %s %v", res.Data, res.Errors)
+	}
+}
+
+func TestPartialDataOnCancel(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		opts     []graphql.SchemaOpt
+		timeout  time.Duration
+		query    string
+		wantData string
+		wantErrs []string
+	}{
+		{
+			name:     "execution timeout",
+			opts:     []graphql.SchemaOpt{graphql.ExecutionTimeout(20 * time.Millisecond), graphql.PartialDataOnCancel()},
+			query:    `{ fast slow nested { value } }`,
+			wantData: `{"fast":"fast","slow":null,"nested":{"value":"value"}}`,
+			wantErrs: []string{"context deadline exceeded [slow]"},
+		},
+		{
+			name:     "context deadline",
+			opts:     []graphql.SchemaOpt{graphql.PartialDataOnCancel()},
+			timeout:  20 * time.Millisecond,
+			query:    `{ fast slow }`,
+			wantData: `{"fast":"fast","slow":null}`,
+			wantErrs: []string{"context deadline exceeded [slow]"},
+		},
+		{
+			name:     "unreached fields",
+			opts:     []graphql.SchemaOpt{graphql.ExecutionTimeout(20 * time.Millisecond), graphql.PartialDataOnCancel()},
+			query:    `{ fast delayed { value slow } }`,
+			wantData: `{"fast":"fast","delayed":null}`,
+			wantErrs: []string{"context deadline exceeded [delayed slow]", "context deadline exceeded [delayed value]"},
+		},
+		{
+			name:     "disabled",
+			opts:     []graphql.SchemaOpt{graphql.ExecutionTimeout(20 * time.Millisecond)},
+			query:    `{ fast slow }`,
+			wantData: ``,
+			wantErrs: []string{"context deadline exceeded []"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := graphql.MustParseSchema(timeoutSchema, &timeoutResolver{}, tc.opts...)
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+			res := s.Exec(ctx, tc.query, "", nil)
+			if string(res.Data) != tc.wantData {
+				t.Errorf("got data %s, want %s", res.Data, tc.wantData)
+			}
+			var errs []string
+			for _, err := range res.Errors {
+				errs = append(errs, fmt.Sprintf("%s %v", err.Message, err.Path))
+			}
+			slices.Sort(errs)
+			if !slices.Equal(errs, tc.wantErrs) {
+				t.Errorf("got errors %q, want %q", errs, tc.wantErrs)
+			}
+		})
+	}
+}
+
+type timeoutSubscriptionResolver struct {
+	timeoutResolver
+}
+
+func (timeoutSubscriptionResolver) Ticks() <-chan int32 {
+	return make(chan int32)
+}
+
+func TestExecutionTimeoutSubscribe(t *testing.T) {
+	s := graphql.MustParseSchema(timeoutSchema+`type Subscription { ticks: Int! }`, &timeoutSubscriptionResolver{},
+		graphql.ExecutionTimeout(20*time.Millisecond), graphql.PartialDataOnCancel())
+	c, err := s.Subscribe(context.Background(), `{ fast slow }`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	res := (<-c).(*graphql.Response)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("execution took %s", elapsed)
+	}
+	if want := `{"fast":"fast","slow":null}`; string(res.Data) != want {
+		t.Errorf("got data %s, want %s", res.Data, want)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].Message != context.DeadlineExceeded.Error() {
+		t.Errorf("got errors %v, want a deadline exceeded error", res.Errors)
+	}
+}
+
+type latePanicResolver struct{}
+
+// Slow panics after the timeout of the field fired.
+func (latePanicResolver) Slow(ctx context.Context) *string {
+	<-ctx.Done()
+	time.Sleep(10 * time.Millisecond)
+	panic("late panic")
+}
+
+func TestFieldTimeoutsLatePanic(t *testing.T) {
+	logged := make(chan any, 1)
+	s := graphql.MustParseSchema(`type Query { slow: String }`, &latePanicResolver{},
+		graphql.FieldTimeouts(map[string]time.Duration{"Query.slow": 10 * time.Millisecond}),
+		graphql.Logger(log.LoggerFunc(func(ctx context.Context, value any) { logged <- value })),
+	)
+	res := s.Exec(context.Background(), `{ slow }`, "", nil)
+	if len(res.Errors) != 1 || res.Errors[0].Message != "graphql: field Query.slow timed out after 10ms" {
+		t.Errorf("got errors %v", res.Errors)
+	}
+	select {
+	case v := <-logged:
+		if v != "late panic" {
+			t.Errorf("got logged panic %v", v)
+		}
+	case <-time.After(time.Second):
+		t.Error("late panic was not logged")
+	}
+}
@@ -77,4 +77,18 @@ func (Tracer) TraceValidation(ctx context.Context) func([]*errors.QueryError) {
 	}
 }
 
+func (Tracer) TraceBatch(ctx context.Context, loader string, keys int) (context.Context, func(error)) {
+	span, spanCtx := opentracing.StartSpanFromContext(ctx, "GraphQL batch: "+loader)
+	span.SetTag("graphql.loader", loader)
+	span.SetTag("graphql.batch.size", keys)
+
+	return spanCtx, func(err error) {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.SetTag("graphql.error", err.Error())
+		}
+		span.Finish()
+	}
+}
+
 func noop(*errors.QueryError) {}
@@ -90,3 +90,18 @@ func (t *Tracer) TraceValidation(ctx context.Context) func([]*errors.QueryError)
 		span.End()
 	}
 }
+
+func (t *Tracer) TraceBatch(ctx context.Context, loader string, keys int) (context.Context, func(error)) {
+	spanCtx, span := t.Tracer.Start(ctx, fmt.Sprintf("GraphQL Batch: %s", loader))
+	span.SetAttributes(
+		attribute.String("graphql.loader", loader),
+		attribute.Int("graphql.batch.size", keys),
+	)
+
+	return spanCtx, func(err error) {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -14,6 +14,7 @@ import (
 func TestInterfaceImplementation(t *testing.T) {
 	var _ tracer.ValidationTracer = &otelgraphql.Tracer{}
 	var _ tracer.Tracer = &otelgraphql.Tracer{}
+	var _ tracer.BatchTracer = &otelgraphql.Tracer{}
 }
 
 func TestTracerOption(t *testing.T) {
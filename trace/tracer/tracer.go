@@ -12,6 +12,7 @@ type (
 	QueryFinishFunc      = func([]*errors.QueryError)
 	FieldFinishFunc      = func(*errors.QueryError)
 	ValidationFinishFunc = func([]*errors.QueryError)
+	BatchFinishFunc      = func(error)
 )
 
 type Tracer interface {
@@ -23,6 +24,12 @@ type ValidationTracer interface {
 	TraceValidation(ctx context.Context) ValidationFinishFunc
 }
 
+// BatchTracer is implemented by tracers which trace the batches loaded by a graphql.Loader.
+// Each batch is traced once, no matter how many fields use its values.
+type BatchTracer interface {
+	TraceBatch(ctx context.Context, loader string, keys int) (context.Context, BatchFinishFunc)
+}
+
 // Deprecated: use [ValidationTracer] instead.
 type LegacyValidationTracer interface {
 	TraceValidation() func([]*errors.QueryError)
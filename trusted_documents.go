@@ -0,0 +1,143 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// TrustedDocumentManifest maps document IDs to the GraphQL documents trusted by the server.
+type TrustedDocumentManifest map[string]string
+
+// ParseTrustedDocumentManifest parses a JSON manifest of trusted documents. It accepts an object
+// mapping document IDs to documents, as generated by Relay or GraphQL Code Generator, and the
+// Apollo persisted query manifest format.
+func ParseTrustedDocumentManifest(data []byte) (TrustedDocumentManifest, error) {
+	var apollo struct {
+		Format     string `json:"format"`
+		Operations []struct {
+			ID   string `json:"id"`
+			Body string `json:"body"`
+		} `json:"operations"`
+	}
+	if err := json.Unmarshal(data, &apollo); err == nil && apollo.Format == "apollo-persisted-query-manifest" {
+		m := make(TrustedDocumentManifest, len(apollo.Operations))
+		for _, op := range apollo.Operations {
+			m[op.ID] = op.Body
+		}
+		return m, nil
+	}
+
+	var m TrustedDocumentManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid trusted document manifest: %w", err)
+	}
+	return m, nil
+}
+
+// TrustedDocuments restricts execution to the documents of the manifest. Requests reference a
+// document by its ID, either with [Request.DocumentID] or with the "sha256Hash" of the
+// "persistedQuery" extension, or send a query which is identical to a document of the manifest.
+// IDs of the form "sha256:<hash>" also match documents by the hash of their content.
+//
+// Unknown document IDs are rejected with an error with the extension code
+// "TRUSTED_DOCUMENT_NOT_FOUND" and other queries with the code "UNTRUSTED_DOCUMENT". Trusted
+// documents are only parsed and validated once.
+//
+// Do not use it together with [TrustedDocumentsLogOnly], otherwise the option added last takes precedence.
+func TrustedDocuments(manifest TrustedDocumentManifest) SchemaOpt {
+	return func(s *Schema) {
+		s.trustedDocuments = newTrustedDocuments(manifest, false, nil)
+	}
+}
+
+// TrustedDocumentsLogOnly is like [TrustedDocuments] but executes queries which are not in the
+// manifest after passing them to report together with the error they would have been rejected
+// with. It is intended for rolling out trusted documents. If report is nil, untrusted queries are
+// logged with the [Logger] of the schema.
+//
+// Do not use it together with [TrustedDocuments], otherwise the option added last takes precedence.
+func TrustedDocumentsLogOnly(manifest TrustedDocumentManifest, report func(ctx context.Context, queryString string, err *errors.QueryError)) SchemaOpt {
+	return func(s *Schema) {
+		s.trustedDocuments = newTrustedDocuments(manifest, true, report)
+	}
+}
+
+type trustedDocuments struct {
+	byID    map[string]*PersistedQuery
+	byHash  map[string]*PersistedQuery
+	byQuery map[string]*PersistedQuery
+	logOnly bool
+	report  func(ctx context.Context, queryString string, err *errors.QueryError)
+}
+
+func newTrustedDocuments(manifest TrustedDocumentManifest, logOnly bool, report func(context.Context, string, *errors.QueryError)) *trustedDocuments {
+	td := &trustedDocuments{
+		byID:    make(map[string]*PersistedQuery, len(manifest)),
+		byHash:  make(map[string]*PersistedQuery, len(manifest)),
+		byQuery: make(map[string]*PersistedQuery, len(manifest)),
+		logOnly: logOnly,
+		report:  report,
+	}
+	for id, doc := range manifest {
+		q, ok := td.byQuery[doc]
+		if !ok {
+			q = &PersistedQuery{Query: doc}
+			sum := sha256.Sum256([]byte(doc))
+			td.byHash[hex.EncodeToString(sum[:])] = q
+			td.byQuery[doc] = q
+		}
+		td.byID[id] = q
+	}
+	return td
+}
+
+// lookup returns the document with the given ID or content hash.
+func (td *trustedDocuments) lookup(id string) (*PersistedQuery, bool) {
+	if q, ok := td.byID[id]; ok {
+		return q, true
+	}
+	q, ok := td.byHash[strings.ToLower(strings.TrimPrefix(id, "sha256:"))]
+	return q, ok
+}
+
+// trustedDocument returns the trusted document of the request. It returns neither a document nor
+// errors if the request looks up a persisted query unknown to the manifest, or if it sends an
+// untrusted query in log-only mode.
+func (s *Schema) trustedDocument(ctx context.Context, req *Request) (*PersistedQuery, []*errors.QueryError) {
+	td := s.trustedDocuments
+	if req.DocumentID != "" {
+		if q, ok := td.lookup(req.DocumentID); ok {
+			return q, nil
+		}
+		return nil, []*errors.QueryError{codedError(fmt.Sprintf("unknown document id %q", req.DocumentID), "TRUSTED_DOCUMENT_NOT_FOUND")}
+	}
+	if req.Query == "" {
+		pq, _ := req.Extensions["persistedQuery"].(map[string]any)
+		if hash, _ := pq["sha256Hash"].(string); hash != "" {
+			if q, ok := td.lookup(hash); ok {
+				return q, nil
+			}
+		}
+		return nil, nil
+	}
+	if q, ok := td.byQuery[req.Query]; ok {
+		return q, nil
+	}
+
+	err := codedError("document is not trusted", "UNTRUSTED_DOCUMENT")
+	if td.logOnly {
+		if td.report != nil {
+			td.report(ctx, req.Query, err)
+		} else {
+			logf(ctx, s.logger, "graphql: %s: %q", err.Message, req.Query)
+		}
+		return nil, nil
+	}
+	return nil, []*errors.QueryError{err}
+}
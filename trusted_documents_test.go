@@ -0,0 +1,203 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+	"github.com/graph-gophers/graphql-go/log"
+)
+
+func TestParseTrustedDocumentManifest(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		data    string
+		want    graphql.TrustedDocumentManifest
+		wantErr bool
+	}{
+		{
+			name: "key-value",
+			data: `{"sha256:abc":"{ hero { name } }","def":"{ hero { id } }"}`,
+			want: graphql.TrustedDocumentManifest{"sha256:abc": "{ hero { name } }", "def": "{ hero { id } }"},
+		},
+		{
+			name: "apollo",
+			data: `{"format":"apollo-persisted-query-manifest","version":1,"operations":[{"id":"abc","name":"Hero","type":"query","body":"query Hero { hero { name } }"}]}`,
+			want: graphql.TrustedDocumentManifest{"abc": "query Hero { hero { name } }"},
+		},
+		{
+			name:    "invalid",
+			data:    `["{ hero { name } }"]`,
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := graphql.ParseTrustedDocumentManifest([]byte(tc.data))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTrustedDocuments(t *testing.T) {
+	const (
+		heroName  = `{ hero { name } }`
+		character = `query($id: ID!) { character(id: $id) { name } }`
+	)
+	manifest := graphql.TrustedDocumentManifest{
+		"hero":      heroName,
+		"character": character,
+	}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.TrustedDocuments(manifest))
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name string
+		req  *graphql.Request
+		want string
+	}{
+		{
+			name: "trusted query",
+			req:  &graphql.Request{Query: heroName},
+			want: `{"data":{"hero":{"name":"R2-D2"}}}`,
+		},
+		{
+			name: "untrusted query",
+			req:  &graphql.Request{Query: `{ hero { id } }`},
+			want: `{"errors":[{"message":"document is not trusted","extensions":{"code":"UNTRUSTED_DOCUMENT"}}]}`,
+		},
+		{
+			name: "document id",
+			req:  &graphql.Request{DocumentID: "character", Variables: map[string]any{"id": "1000"}},
+			want: `{"data":{"character":{"name":"Luke Skywalker"}}}`,
+		},
+		{
+			name: "document id validates variables",
+			req:  &graphql.Request{DocumentID: "character"},
+			want: `{"errors":[{"message":"Variable \"id\" has invalid value null.\nExpected type \"ID!\", found null.","locations":[{"line":1,"column":7}]}]}`,
+		},
+		{
+			name: "content hash",
+			req:  &graphql.Request{DocumentID: "sha256:" + sha256Hex(heroName)},
+			want: `{"data":{"hero":{"name":"R2-D2"}}}`,
+		},
+		{
+			name: "persisted query hash",
+			req:  &graphql.Request{Extensions: persistedQueryExt(sha256Hex(heroName))},
+			want: `{"data":{"hero":{"name":"R2-D2"}}}`,
+		},
+		{
+			name: "unknown document id",
+			req:  &graphql.Request{DocumentID: "unknown"},
+			want: `{"errors":[{"message":"unknown document id \"unknown\"","extensions":{"code":"TRUSTED_DOCUMENT_NOT_FOUND"}}]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := responseJSON(t, schema.ExecRequest(ctx, tc.req)); got != tc.want {
+				t.Errorf("unexpected response:\n got: %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("Exec", func(t *testing.T) {
+		resp := schema.Exec(ctx, `{ hero { id } }`, "", nil)
+		if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "UNTRUSTED_DOCUMENT" {
+			t.Errorf("expected an untrusted document error, got %s", responseJSON(t, resp))
+		}
+	})
+}
+
+func TestTrustedDocumentsSubscribe(t *testing.T) {
+	schema := graphql.MustParseSchema(schema, &rootResolver{
+		helloSaidResolver: &helloSaidResolver{upstream: closedUpstream(&helloSaidEventResolver{msg: "Hello world!"})},
+	}, graphql.TrustedDocuments(graphql.TrustedDocumentManifest{"hello": `subscription { helloSaid { msg } }`}))
+
+	c, err := schema.Subscribe(context.Background(), `subscription { helloSaid { msg } }`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := responseJSON(t, (<-c).(*graphql.Response)), `{"data":{"helloSaid":{"msg":"Hello world!"}}}`; got != want {
+		t.Errorf("unexpected response:\n got: %s\nwant: %s", got, want)
+	}
+
+	c, err = schema.Subscribe(context.Background(), `subscription { helloSaid { msg } } `, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := responseJSON(t, (<-c).(*graphql.Response)), `{"errors":[{"message":"document is not trusted","extensions":{"code":"UNTRUSTED_DOCUMENT"}}]}`; got != want {
+		t.Errorf("unexpected response:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+// messageLogger records the messages logged by a schema.
+type messageLogger struct {
+	log.LoggerFunc
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *messageLogger) Logf(_ context.Context, format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func TestTrustedDocumentsLogOnlyDefaultReport(t *testing.T) {
+	logger := &messageLogger{}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{},
+		graphql.Logger(logger),
+		graphql.TrustedDocumentsLogOnly(graphql.TrustedDocumentManifest{"hero": `{ hero { name } }`}, nil))
+
+	for _, q := range []string{`{ hero { name } }`, `{ hero { id } }`} {
+		if resp := schema.Exec(context.Background(), q, "", nil); len(resp.Errors) != 0 {
+			t.Fatalf("unexpected errors: %v", resp.Errors)
+		}
+	}
+	if want := []string{`graphql: document is not trusted: "{ hero { id } }"`}; !reflect.DeepEqual(logger.messages, want) {
+		t.Errorf("expected messages %q, got %q", want, logger.messages)
+	}
+}
+
+func TestTrustedDocumentsLogOnly(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		reported []string
+	)
+	report := func(_ context.Context, queryString string, err *qerrors.QueryError) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, queryString+": "+err.Extensions["code"].(string))
+	}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{},
+		graphql.TrustedDocumentsLogOnly(graphql.TrustedDocumentManifest{"hero": `{ hero { name } }`}, report))
+
+	for _, q := range []string{`{ hero { name } }`, `{ hero { id } }`} {
+		if resp := schema.Exec(context.Background(), q, "", nil); len(resp.Errors) != 0 {
+			t.Fatalf("unexpected errors: %v", resp.Errors)
+		}
+	}
+	if want := []string{"{ hero { id } }: UNTRUSTED_DOCUMENT"}; !reflect.DeepEqual(reported, want) {
+		t.Errorf("expected reports %q, got %q", want, reported)
+	}
+
+	resp := schema.ExecRequest(context.Background(), &graphql.Request{DocumentID: "unknown"})
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "TRUSTED_DOCUMENT_NOT_FOUND" {
+		t.Errorf("expected unknown document ids to be rejected, got %s", responseJSON(t, resp))
+	}
+}
+
+func TestDocumentIDWithoutTrustedDocuments(t *testing.T) {
+	resp := starwarsSchema.ExecRequest(context.Background(), &graphql.Request{DocumentID: "hero"})
+	if got, want := responseJSON(t, resp), `{"errors":[{"message":"document ids are not supported by the schema"}]}`; got != want {
+		t.Errorf("unexpected response:\n got: %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,47 @@
+package graphql
+
+import (
+	"fmt"
+	"io"
+)
+
+// Upload is a file uploaded with a [GraphQL multipart request]. It has to be added to a schema
+// via "scalar Upload" since it is not a predeclared GraphQL type. Uploads can only be used as
+// input values; transports such as relay.Handler bind the uploaded files to the variables
+// referenced by the request.
+//
+// [GraphQL multipart request]: https://github.com/jaydenseric/graphql-multipart-request-spec
+type Upload struct {
+	// File provides the content of the uploaded file. It is only valid until the
+	// request that uploaded it completes.
+	File        io.Reader
+	Filename    string
+	ContentType string
+	Size        int64
+}
+
+// ImplementsGraphQLType maps this custom Go type
+// to the graphql scalar type in the schema.
+func (Upload) ImplementsGraphQLType(name string) bool {
+	return name == "Upload"
+}
+
+// UnmarshalGraphQL is a custom unmarshaler for Upload
+//
+// This function will be called whenever you use the
+// Upload scalar as an input
+func (u *Upload) UnmarshalGraphQL(input any) error {
+	switch input := input.(type) {
+	case Upload:
+		*u = input
+		return nil
+	case *Upload:
+		if input == nil {
+			return fmt.Errorf("wrong type for Upload: %T", input)
+		}
+		*u = *input
+		return nil
+	default:
+		return fmt.Errorf("wrong type for Upload: %T", input)
+	}
+}
@@ -0,0 +1,45 @@
+package graphql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/decode"
+)
+
+func TestUpload_ImplementsUnmarshaler(t *testing.T) {
+	// assert *Upload implements decode.Unmarshaler interface
+	var _ decode.Unmarshaler = (*graphql.Upload)(nil)
+}
+
+func TestUpload_ImplementsGraphQLType(t *testing.T) {
+	u := &graphql.Upload{}
+
+	if u.ImplementsGraphQLType("String") {
+		t.Error("Type *Upload must not claim to implement GraphQL type 'String'")
+	}
+
+	if !u.ImplementsGraphQLType("Upload") {
+		t.Error("Failed asserting *Upload implements GraphQL type Upload")
+	}
+}
+
+func TestUpload_UnmarshalGraphQL(t *testing.T) {
+	in := graphql.Upload{File: strings.NewReader("content"), Filename: "a.txt", ContentType: "text/plain", Size: 7}
+
+	for _, input := range []any{in, &in} {
+		var u graphql.Upload
+		if err := u.UnmarshalGraphQL(input); err != nil {
+			t.Fatal(err)
+		}
+		if u.Filename != "a.txt" || u.ContentType != "text/plain" || u.Size != 7 || u.File == nil {
+			t.Errorf("unexpected upload %+v", u)
+		}
+	}
+
+	var u graphql.Upload
+	if err := u.UnmarshalGraphQL("a.txt"); err == nil || err.Error() != "wrong type for Upload: string" {
+		t.Errorf("expected wrong type error, got %v", err)
+	}
+}